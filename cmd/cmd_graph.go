@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cmdGraph = &cobra.Command{
+	Use:   "graph",
+	Short: "Work with graph files.",
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdGraph)
+}
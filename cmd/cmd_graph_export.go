@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/actionforge/actrun-cli/core"
+	"github.com/spf13/cobra"
+)
+
+var flagExportFormat string
+
+var cmdGraphExport = &cobra.Command{
+	Use:   "export [graph-file]",
+	Short: "Export a graph file as a diagram.",
+	Long: `Exports the nodes, execution connections and data connections of a graph file as a diagram,
+with the groups of the graph as clusters. The diagram is written to stdout.
+
+The dot format can be rendered with Graphviz, the mermaid format is rendered by GitHub in markdown files
+and comments. The svg format requires the 'dot' command of Graphviz.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := core.ExportGraphFromFile(expandPath(args[0]), flagExportFormat, os.Stdout)
+		if err != nil {
+			core.PrintError(args[0], err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cmdGraphExport.Flags().StringVar(&flagExportFormat, "format", "dot", fmt.Sprintf("Format of the diagram, one of: %s", strings.Join(core.ExportFormats, ", ")))
+	cmdGraph.AddCommand(cmdGraphExport)
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cmdImport = &cobra.Command{
+	Use:   "import",
+	Short: "Convert files of other tools into graph files.",
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdImport)
+}
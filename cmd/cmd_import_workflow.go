@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/actionforge/actrun-cli/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagImportJob    string
+	flagImportOutput string
+)
+
+var cmdImportWorkflow = &cobra.Command{
+	Use:   "workflow [workflow-file]",
+	Short: "Convert a job of a GitHub Actions workflow into a graph file.",
+	Long: `Converts the steps of a job of a GitHub Actions workflow into a graph that runs them one after
+another. The graph starts with a 'core/gh-start@v1' node that is connected for the triggers of the
+workflow. Steps with 'uses' become action nodes, steps with 'run' become 'core/run@v1' nodes, and
+the 'if' of a step becomes a 'core/branch@v1' node in front of it. The env vars of the workflow,
+the job and the step are set on the nodes.
+
+Constructs without an equivalent in graphs, like a matrix or the 'steps' context, are reported as
+warnings. The graph is written to stdout, or to the file of --output.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		graph, warnings, err := core.ImportWorkflowFile(expandPath(args[0]), flagImportJob)
+		if err != nil {
+			core.PrintError(args[0], err)
+			os.Exit(1)
+		}
+
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "⚠️ %s\n", warning)
+		}
+
+		if flagImportOutput == "" {
+			fmt.Print(string(graph))
+			return
+		}
+
+		err = os.WriteFile(expandPath(flagImportOutput), graph, 0644)
+		if err != nil {
+			core.PrintError(args[0], core.CreateErr(nil, err, "failed to write graph file '%s'", flagImportOutput))
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Imported the workflow to %s with %d warning(s).\n", flagImportOutput, len(warnings))
+	},
+}
+
+func init() {
+	cmdImportWorkflow.Flags().StringVar(&flagImportJob, "job", "", "Id of the job to import, can be omitted if the workflow has a single job")
+	cmdImportWorkflow.Flags().StringVarP(&flagImportOutput, "output", "o", "", "File to write the graph to (default: stdout)")
+	cmdImport.AddCommand(cmdImportWorkflow)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/actionforge/actrun-cli/core"
+	"github.com/spf13/cobra"
+)
+
+var flagInspectFormat string
+
+var cmdInspect = &cobra.Command{
+	Use:   "inspect [graph-file]",
+	Short: "Describe the interface of a graph file.",
+	Long: `Prints the entry node, the declared inputs and outputs of a graph file, and the secrets,
+environment variables and GitHub Actions used by its nodes, including the nodes in its groups.
+The graph is not executed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagInspectFormat != "text" && flagInspectFormat != "json" {
+			fmt.Fprintf(os.Stderr, "unknown format '%s', use 'text' or 'json'\n", flagInspectFormat)
+			os.Exit(1)
+		}
+
+		gi, err := core.InspectGraphFromFile(expandPath(args[0]))
+		if err != nil {
+			core.PrintError(args[0], err)
+			os.Exit(1)
+		}
+
+		if flagInspectFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(gi)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printGraphInterface(args[0], gi)
+	},
+}
+
+func printGraphInterface(graphFile string, gi core.GraphInterface) {
+	fmt.Printf("Graph: %s\n", graphFile)
+	fmt.Printf("Entry: %s (%s)\n", gi.Entry.Id, gi.Entry.Type)
+
+	printGraphPorts("Inputs", gi.Inputs)
+	printGraphPorts("Outputs", gi.Outputs)
+	printNames("Secrets", gi.Secrets)
+	printNames("Environment variables", gi.Env)
+	printNames("GitHub Actions", gi.GitHubActions)
+}
+
+func printGraphPorts(title string, ports []core.GraphPort) {
+	fmt.Printf("\n%s:\n", title)
+	if len(ports) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	for _, p := range ports {
+		var attrs []string
+		if p.Exec {
+			attrs = append(attrs, "exec")
+		} else {
+			attrs = append(attrs, p.Type)
+		}
+		if p.Required {
+			attrs = append(attrs, "required")
+		}
+		if p.Default != nil {
+			attrs = append(attrs, fmt.Sprintf("default: %v", p.Default))
+		}
+
+		line := fmt.Sprintf("  %s (%s)", p.Id, strings.Join(attrs, ", "))
+		if p.Desc != "" {
+			line += " - " + p.Desc
+		}
+		fmt.Println(line)
+	}
+}
+
+func printNames(title string, names []string) {
+	fmt.Printf("\n%s:\n", title)
+	if len(names) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	for _, name := range names {
+		fmt.Printf("  %s\n", name)
+	}
+}
+
+func init() {
+	cmdInspect.Flags().StringVar(&flagInspectFormat, "format", "text", "Output format, 'text' or 'json'")
+	cmdRoot.AddCommand(cmdInspect)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/actionforge/actrun-cli/core"
+	"github.com/spf13/cobra"
+)
+
+var flagMigrateDryRun bool
+
+var cmdMigrate = &cobra.Command{
+	Use:   "migrate [graph-file]",
+	Short: "Upgrade the deprecated node types of a graph file.",
+	Long: `Migrates the nodes of a graph file whose node types are deprecated to the latest version
+of their node type, including the nodes in the inline graphs of groups. The migration renames
+the ports, connections and option values that changed between the versions.
+
+The changes are printed as a diff and the file is rewritten in place. Use --dry_run to only
+print the diff.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		steps, diff, err := core.MigrateGraphFile(expandPath(args[0]), flagMigrateDryRun)
+		if err != nil {
+			core.PrintError(args[0], err)
+			os.Exit(1)
+		}
+
+		if len(steps) == 0 {
+			fmt.Println("✅ Graph uses no deprecated node types.")
+			return
+		}
+
+		fmt.Print(diff)
+		fmt.Println()
+		for _, step := range steps {
+			fmt.Printf("  %s: %s -> %s\n", step.Node, step.From, step.To)
+		}
+
+		if flagMigrateDryRun {
+			fmt.Printf("\n%d node migration(s) pending, the graph file was not changed.\n", len(steps))
+		} else {
+			fmt.Printf("\n✅ Applied %d node migration(s) to %s.\n", len(steps), args[0])
+		}
+	},
+}
+
+func init() {
+	cmdMigrate.Flags().BoolVar(&flagMigrateDryRun, "dry_run", false, "Print the changes without rewriting the graph file")
+	cmdRoot.AddCommand(cmdMigrate)
+}
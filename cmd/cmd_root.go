@@ -7,6 +7,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/actionforge/actrun-cli/build"
 	"github.com/actionforge/actrun-cli/core"
@@ -28,6 +30,17 @@ var (
 	flagSessionToken       string
 	flagEnvFile            string
 	flagCreateDebugSession bool
+	flagRunDir             string
+	flagResume             string
+	flagDryRun             bool
+	flagEvents             string
+	flagOtlpEndpoint       string
+	flagCacheDir           string
+	flagInputs             []string
+	flagInputsFile         string
+	flagGhEmulate          bool
+	flagEvent              string
+	flagEventPayload       string
 
 	finalConfigFile         string
 	finalConcurrency        string
@@ -143,6 +156,14 @@ var cmdRoot = &cobra.Command{
 			return errors.New("both --session_token and --create_debug_session cannot be used together")
 		} else if finalCreateDebugSession && finalGraphFile == "" {
 			return errors.New("when using --create_debug_session, a graph file must be specified")
+		} else if flagDryRun && finalGraphFile == "" {
+			return errors.New("when using --dry_run, a graph file must be specified")
+		} else if flagResume != "" && flagRunDir == "" {
+			return errors.New("--resume requires the --run_dir of the run to resume")
+		} else if flagGhEmulate && finalGraphFile == "" {
+			return errors.New("when using --gh_emulate, a graph file must be specified")
+		} else if !flagGhEmulate && (cmd.Flags().Changed("event") || flagEventPayload != "") {
+			return errors.New("--event and --event_payload require --gh_emulate")
 		}
 
 		return nil
@@ -172,16 +193,90 @@ func cmdRootRun(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	err := core.RunGraphFromFile(context.Background(), finalGraphFile, core.RunOpts{
+	if flagDryRun {
+		err := core.PlanGraphFromFile(finalGraphFile, os.Stdout)
+		if err != nil {
+			core.PrintError(finalGraphFile, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cleanup := func() {}
+	if flagGhEmulate {
+		var err error
+		cleanup, err = core.EmulateGitHubRunner(core.GhEmulateOpts{
+			Event:        flagEvent,
+			EventPayload: flagEventPayload,
+		})
+		if err != nil {
+			core.PrintError(finalGraphFile, err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, cancelled := notifyCancel()
+
+	err := core.RunGraphFromFile(ctx, finalGraphFile, core.RunOpts{
 		ConfigFile:      finalConfigFile,
 		OverrideSecrets: nil,
 		OverrideInputs:  nil,
 		Args:            finalGraphArgs,
+		Inputs:          flagInputs,
+		InputsFile:      flagInputsFile,
+		RunDir:          flagRunDir,
+		ResumeRunId:     flagResume,
+		Events:          flagEvents,
+		OtlpEndpoint:    flagOtlpEndpoint,
+		CacheDir:        flagCacheDir,
+		GhEmulate:       flagGhEmulate,
 	}, nil)
 	if err != nil {
 		core.PrintError(finalGraphFile, err)
-		os.Exit(1)
 	}
+	cleanup()
+
+	select {
+	case sig := <-cancelled:
+		os.Exit(exitCodeForSignal(sig))
+	default:
+		if err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// notifyCancel returns a context that is cancelled by the first SIGINT or SIGTERM.
+// Child processes then get `utils.ProcessGracePeriod` to exit. A second signal
+// kills them and exits immediately. The signal that cancelled the run is sent to the returned channel.
+func notifyCancel() (context.Context, <-chan os.Signal) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	cancelled := make(chan os.Signal, 1)
+	go func() {
+		sig := <-signals
+		utils.LogErr.Warnf("received %s, cancelling the run. Send the signal again to exit immediately.\n", sig)
+		cancelled <- sig
+		cancel()
+
+		sig = <-signals
+		utils.LogErr.Errorf("received %s, exiting\n", sig)
+		utils.KillProcessGroups()
+		os.Exit(exitCodeForSignal(sig))
+	}()
+
+	return ctx, cancelled
+}
+
+// exitCodeForSignal follows the shell convention of 128 + signal number for processes ended by a signal.
+func exitCodeForSignal(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
 }
 
 func Execute() {
@@ -212,6 +307,17 @@ func init() {
 	cmdRoot.Flags().StringVar(&flagConcurrency, "concurrency", "", "Enable or disable concurrency")
 	cmdRoot.Flags().StringVar(&flagSessionToken, "session_token", "", "The session token from your browser")
 	cmdRoot.Flags().BoolVar(&flagCreateDebugSession, "create_debug_session", false, "Create a debug session by connecting to the web app")
+	cmdRoot.Flags().StringVar(&flagRunDir, "run_dir", "", "Directory to write a journal of the run to, so the run can be resumed")
+	cmdRoot.Flags().StringVar(&flagResume, "resume", "", "The id of an interrupted run in --run_dir to resume")
+	cmdRoot.Flags().BoolVar(&flagDryRun, "dry_run", false, "Print the execution plan of the graph without executing any node")
+	cmdRoot.Flags().StringVar(&flagEvents, "events", "", "File path or file descriptor number to write run events to as JSON Lines")
+	cmdRoot.Flags().StringVar(&flagOtlpEndpoint, "otlp_endpoint", "", "Base URL of an OpenTelemetry collector to export a span per executed node to via OTLP/HTTP")
+	cmdRoot.Flags().StringArrayVar(&flagInputs, "input", nil, "Value of a graph input as 'name=value', can be repeated")
+	cmdRoot.Flags().StringVar(&flagInputsFile, "inputs_file", "", "JSON or YAML file with the values of graph inputs")
+	cmdRoot.Flags().StringVar(&flagCacheDir, "cache_dir", "", "Directory to cache the results of nodes with 'cache: true' in (default: the user's cache directory)")
+	cmdRoot.Flags().BoolVar(&flagGhEmulate, "gh_emulate", false, "Emulate a GitHub Actions runner to run a graph that starts with 'core/gh-start@v1' locally")
+	cmdRoot.Flags().StringVar(&flagEvent, "event", "push", "Name of the event that triggers the graph when using --gh_emulate")
+	cmdRoot.Flags().StringVar(&flagEventPayload, "event_payload", "", "JSON file with the webhook payload of the event when using --gh_emulate")
 
 	// disable interspersed flag parsing to allow passing arbitrary flags to graphs.
 	// it stops cobra from parsing flags once it hits positional argument
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/actionforge/actrun-cli/core"
+	"github.com/spf13/cobra"
+)
+
+var cmdSchema = &cobra.Command{
+	Use:   "schema [act|node]",
+	Short: "Print the JSON Schema of graph files or node definitions.",
+	Long: `Prints a JSON Schema to stdout, generated from the types used by actrun.
+
+'act' describes the .act graph file format. The inputs of each node are described by
+the input definitions of its node type, including the values of option inputs.
+'node' describes the .yml definitions of node types.
+
+The schemas can be used by editors for autocompletion, or to validate graph files
+without running actrun.`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: core.SchemaKinds,
+	Run: func(cmd *cobra.Command, args []string) {
+		var schema map[string]any
+		switch args[0] {
+		case "act":
+			schema = core.ActSchema()
+		case "node":
+			schema = core.NodeSchema()
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(schema)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdSchema)
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/user"
+	"path/filepath"
 	"strings"
 
 	"github.com/actionforge/actrun-cli/core"
@@ -12,11 +14,17 @@ import (
 	"go.yaml.in/yaml/v4"
 )
 
+var flagValidateFormat string
+
 var cmdValidate = &cobra.Command{
 	Use:   "validate [graph-file]",
 	Short: "Validate a graph file.",
-	Long:  `Validates the structure, types, connections, and required inputs of an ActionForge graph file without executing it.`,
-	Args:  cobra.ArbitraryArgs,
+	Long: `Validates the structure, types, connections, and required inputs of an ActionForge graph file without executing it.
+
+Besides errors that prevent the graph from loading, the graph is checked for nodes that are never executed,
+required inputs without a value, cycles between data nodes, connections that can't be converted at runtime,
+and inputs that read outputs of nodes that are not executed before them. Warnings don't fail the validation.`,
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		graphFile, _ := u.ResolveCliParam("graph_file", u.ResolveCliParamOpts{
@@ -31,30 +39,41 @@ var cmdValidate = &cobra.Command{
 			} // if no args, let validateGraph handle the error
 		}
 
-		err := validateGraph(graphFile)
+		var err error
+		switch flagValidateFormat {
+		case "text":
+			err = validateGraph(graphFile)
+		case "json":
+			err = validateGraphJson(graphFile)
+		default:
+			fmt.Fprintf(os.Stderr, "unknown format '%s', use 'text' or 'json'\n", flagValidateFormat)
+			os.Exit(1)
+		}
 		if err != nil {
 			os.Exit(1)
 		}
 	},
 }
 
+// validationReport is the result of `validate --format json`.
+type validationReport struct {
+	File     string         `json:"file"`
+	Valid    bool           `json:"valid"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Findings []core.Finding `json:"findings"`
+}
+
 func validateGraph(filePath string) error {
 	fmt.Printf("Validating '%s'...\n", filePath)
 
-	content, err := os.ReadFile(expandPath(filePath))
+	graphYaml, err := loadGraphYaml(filePath)
 	if err != nil {
-		fmt.Printf("Error reading file: %v\n", err)
+		fmt.Printf("%v\n", err)
 		return err
 	}
 
-	var graphYaml map[string]any
-	err = yaml.Unmarshal(content, &graphYaml)
-	if err != nil {
-		fmt.Printf("Error parsing YAML: %v\n", err)
-		return err
-	}
-
-	_, errs := core.LoadGraph(graphYaml, nil, "", true)
+	ag, errs := core.LoadGraph(graphYaml, nil, "", core.GraphLoadContext{Dir: filepath.Dir(filePath)}, true)
 
 	if len(errs) > 0 {
 		fmt.Printf("\n❌ Validation failed with %d error(s):\n", len(errs))
@@ -70,10 +89,104 @@ func validateGraph(filePath string) error {
 		return fmt.Errorf("validation failed")
 	}
 
+	var findingErrs, findingWarnings []core.Finding
+	for _, f := range core.AnalyzeGraph(&ag) {
+		if f.Severity == core.FindingError {
+			findingErrs = append(findingErrs, f)
+		} else {
+			findingWarnings = append(findingWarnings, f)
+		}
+	}
+
+	if len(findingWarnings) > 0 {
+		fmt.Printf("\n⚠️ Found %d warning(s):\n", len(findingWarnings))
+
+		for i, f := range findingWarnings {
+			fmt.Printf("\n--- Warning %d ---\n", i+1)
+			fmt.Println(f.Message)
+			if f.Hint != "" {
+				fmt.Printf("hint: %s\n", f.Hint)
+			}
+		}
+	}
+
+	if len(findingErrs) > 0 {
+		fmt.Printf("\n❌ Validation failed with %d error(s):\n", len(findingErrs))
+
+		for i, f := range findingErrs {
+			fmt.Printf("\n--- Error %d ---\n", i+1)
+			fmt.Printf("%v\n", core.CreateErr(nil, nil, "%s", f.Message).SetHint("%s", f.Hint))
+		}
+		return fmt.Errorf("validation failed")
+	}
+
 	fmt.Println("\n✅ Graph is valid.")
 	return nil
 }
 
+// validateGraphJson validates a graph file like `validateGraph`, but prints
+// the result as a single JSON object, e.g. to turn it into CI annotations.
+func validateGraphJson(filePath string) error {
+	report := validationReport{
+		File:     filePath,
+		Findings: []core.Finding{},
+	}
+
+	graphYaml, err := loadGraphYaml(filePath)
+	if err != nil {
+		report.Findings = append(report.Findings, core.Finding{Severity: core.FindingError, Message: err.Error()})
+	} else {
+		ag, errs := core.LoadGraph(graphYaml, nil, "", core.GraphLoadContext{Dir: filepath.Dir(filePath)}, true)
+		for _, e := range errs {
+			f := core.Finding{Severity: core.FindingError, Message: e.Error()}
+			if leafErr, ok := e.(*core.LeafError); ok {
+				f.Message = leafErr.ErrorWithCauses()
+				f.Hint = leafErr.Hint
+			}
+			report.Findings = append(report.Findings, f)
+		}
+		if len(errs) == 0 {
+			report.Findings = append(report.Findings, core.AnalyzeGraph(&ag)...)
+		}
+	}
+
+	for _, f := range report.Findings {
+		if f.Severity == core.FindingError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(report)
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("validation failed")
+	}
+	return nil
+}
+
+func loadGraphYaml(filePath string) (map[string]any, error) {
+	content, err := os.ReadFile(expandPath(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading file: %w", err)
+	}
+
+	var graphYaml map[string]any
+	err = yaml.Unmarshal(content, &graphYaml)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing YAML: %w", err)
+	}
+	return graphYaml, nil
+}
+
 func expandPath(path string) string {
 	if strings.HasPrefix(path, "~") {
 		usr, err := user.Current()
@@ -85,5 +198,6 @@ func expandPath(path string) string {
 }
 
 func init() {
+	cmdValidate.Flags().StringVar(&flagValidateFormat, "format", "text", "Output format of the result, 'text' or 'json'")
 	cmdRoot.AddCommand(cmdValidate)
 }
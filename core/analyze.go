@@ -0,0 +1,478 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type FindingSeverity string
+
+const (
+	FindingError   FindingSeverity = "error"
+	FindingWarning FindingSeverity = "warning"
+)
+
+// Finding is a problem in a graph that is found without executing it.
+// Errors make the graph fail when it runs, warnings point to nodes
+// that most likely don't behave as intended.
+type Finding struct {
+	Severity FindingSeverity `json:"severity"`
+	// Full path of the node the finding refers to.
+	Node    string `json:"node,omitempty"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// unknownOutput describes how the type of an output of type `unknown`
+// follows from the value of one of the inputs of its node.
+type unknownOutput struct {
+	input InputId
+	// If true, the output has the type of the elements of the input.
+	elem bool
+}
+
+var unknownOutputs = map[string]map[OutputId]unknownOutput{
+	"core/for-each-loop@v1":            {"value": {input: "input", elem: true}},
+	"core/concurrent-for-each-loop@v1": {"value": {input: "input", elem: true}},
+	"core/array-get@v1":                {"result": {input: "array", elem: true}},
+	"core/array-add@v1":                {"array": {input: "array"}},
+	"core/array-append@v1":             {"array": {input: "array1"}},
+	"core/freeze@v1":                   {"value": {input: "init"}},
+}
+
+type analyzer struct {
+	findings []Finding
+	entry    NodeBaseInterface
+	// Nodes that are reached by following the execution connections from the entry.
+	reached map[NodeBaseInterface]bool
+}
+
+// AnalyzeGraph checks a loaded graph, including the graphs of its groups, for
+// problems that `LoadGraph` doesn't detect since they depend on the graph as
+// a whole:
+//
+//   - execution nodes that are not reachable from the entry,
+//   - required inputs of executed nodes without a value or a connection,
+//   - cycles between data nodes,
+//   - connections from outputs of type `unknown` with values that can never
+//     be converted to the type of the connected input,
+//   - inputs that read outputs of execution nodes that are not executed
+//     before the node of the input.
+func AnalyzeGraph(ag *ActionGraph) []Finding {
+	a := &analyzer{
+		reached: map[NodeBaseInterface]bool{},
+	}
+
+	steps, err := PlanGraph(ag)
+	if err != nil {
+		a.add(FindingError, nil, err.Error(), "")
+		return a.findings
+	}
+	for _, step := range steps {
+		a.reached[step.Node] = true
+	}
+	a.entry = steps[0].Node
+
+	a.analyzeGraph(ag)
+	return a.findings
+}
+
+func (a *analyzer) add(severity FindingSeverity, node NodeBaseInterface, msg string, hint string) {
+	f := Finding{
+		Severity: severity,
+		Message:  msg,
+		Hint:     hint,
+	}
+	if node != nil {
+		f.Node = node.GetFullPath()
+	}
+	a.findings = append(a.findings, f)
+}
+
+func (a *analyzer) analyzeGraph(ag *ActionGraph) {
+	for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+		node := ag.Nodes[nodeId]
+
+		a.checkReachable(node)
+		a.checkRequiredInputs(node)
+		a.checkConversions(node)
+		a.checkExecutionOrder(node)
+	}
+
+	a.checkDataCycles(ag)
+
+	for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+		node := ag.Nodes[nodeId]
+		if isGroupNode(node) && node.GetGraph() != nil {
+			a.analyzeGraph(node.GetGraph())
+		}
+	}
+}
+
+func (a *analyzer) checkReachable(node NodeBaseInterface) {
+	if !hasExecutionPorts(node) || isGroupPorts(node) || a.reached[node] {
+		return
+	}
+	a.add(FindingWarning, node,
+		fmt.Sprintf("node '%s' is never executed, it's not reachable from the entry", node.GetFullPath()),
+		"connect one of its execution inputs or remove the node")
+}
+
+func (a *analyzer) checkRequiredInputs(node NodeBaseInterface) {
+	inputs, ok := node.(HasInputsInterface)
+	if !ok || isGroupPorts(node) {
+		return
+	}
+	// a node that is never executed doesn't fail, it's already reported by `checkReachable`
+	if hasExecutionPorts(node) && !a.reached[node] {
+		return
+	}
+
+	inputDefs := inputs.GetInputDefs()
+	for _, inputId := range slices.Sorted(maps.Keys(inputDefs)) {
+		inputDef := inputDefs[inputId]
+		if inputDef.Exec || inputDef.Array || !inputDef.Required || inputDef.Default != nil {
+			continue
+		}
+		if _, connected := inputs.GetDataSources()[inputId]; connected {
+			continue
+		}
+		if inputs.GetInputValues()[inputId] != nil {
+			continue
+		}
+		a.add(FindingError, node,
+			fmt.Sprintf("required input '%s' of node '%s' has neither a value nor a connection", inputId, node.GetFullPath()),
+			"set a value for the input or connect it to an output")
+	}
+}
+
+func (a *analyzer) checkConversions(node NodeBaseInterface) {
+	inputs, ok := node.(HasInputsInterface)
+	if !ok {
+		return
+	}
+
+	dataSources := inputs.GetDataSources()
+	for _, inputId := range slices.Sorted(maps.Keys(dataSources)) {
+		ds := dataSources[inputId]
+
+		declared, ok := outputType(ds)
+		if !ok || declared != "unknown" {
+			// outputs of a known type are checked when the graph is loaded
+			continue
+		}
+
+		srcType := resolveOutputType(ds, 0)
+		if !isConcreteType(srcType) {
+			continue
+		}
+
+		dstType, ok := inputType(node, inputId)
+		if !ok || dstType == "unknown" {
+			continue
+		}
+
+		if !PortsAreCompatible(PortType{PortType: srcType}, PortType{PortType: dstType}) {
+			a.add(FindingError, node,
+				fmt.Sprintf("input '%s' of node '%s' is connected to output '%s' of node '%s', which holds a '%s' that can't be converted to '%s'",
+					inputId, node.GetFullPath(), outputPortId(ds), ds.SrcNode.GetFullPath(), srcType, dstType),
+				"connect the input to an output of a compatible type")
+		}
+	}
+}
+
+func (a *analyzer) checkExecutionOrder(node NodeBaseInterface) {
+	if !a.reached[node] || isGroupPorts(node) {
+		return
+	}
+
+	producers := map[NodeBaseInterface]DataSource{}
+	collectProducers(node, producers, map[NodeBaseInterface]bool{})
+
+	var sorted []NodeBaseInterface
+	for producer := range producers {
+		if producer != node {
+			sorted = append(sorted, producer)
+		}
+	}
+	slices.SortFunc(sorted, func(x, y NodeBaseInterface) int {
+		return strings.Compare(x.GetFullPath(), y.GetFullPath())
+	})
+
+	for _, producer := range sorted {
+		ds := producers[producer]
+		if !a.reached[producer] {
+			a.add(FindingWarning, node,
+				fmt.Sprintf("node '%s' reads output '%s' of node '%s', which is never executed",
+					node.GetFullPath(), outputPortId(ds), producer.GetFullPath()),
+				"connect the input to a node that is executed before it")
+		} else if !executionReachable(a.entry, producer, node) && !executionReachable(producer, node, nil) {
+			a.add(FindingWarning, node,
+				fmt.Sprintf("node '%s' reads output '%s' of node '%s', which is only executed after it",
+					node.GetFullPath(), outputPortId(ds), producer.GetFullPath()),
+				"connect the input to a node that is executed before it")
+		}
+	}
+}
+
+// checkDataCycles reports data nodes that depend on their own output.
+func (a *analyzer) checkDataCycles(ag *ActionGraph) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[NodeBaseInterface]int{}
+	reported := map[string]bool{}
+
+	var visit func(node NodeBaseInterface, path []NodeBaseInterface)
+	visit = func(node NodeBaseInterface, path []NodeBaseInterface) {
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, src := range dataSourceNodes(node) {
+			if hasExecutionPorts(src) || isGroupPorts(src) {
+				continue
+			}
+
+			switch state[src] {
+			case visiting:
+				cycle := path[slices.Index(path, src):]
+				names := make([]string, 0, len(cycle)+1)
+				for _, n := range cycle {
+					names = append(names, n.GetFullPath())
+				}
+				key := strings.Join(slices.Sorted(slices.Values(names)), ",")
+				if !reported[key] {
+					reported[key] = true
+					names = append(names, src.GetFullPath())
+					a.add(FindingError, src,
+						fmt.Sprintf("data nodes depend on their own output: %s", strings.Join(names, " <- ")),
+						"remove one of the connections of the cycle")
+				}
+			case 0:
+				visit(src, path)
+			}
+		}
+		state[node] = done
+	}
+
+	for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+		node := ag.Nodes[nodeId]
+		if state[node] == 0 && !hasExecutionPorts(node) {
+			visit(node, nil)
+		}
+	}
+}
+
+// collectProducers collects the execution nodes whose outputs `node` reads, directly or via data nodes.
+func collectProducers(node NodeBaseInterface, producers map[NodeBaseInterface]DataSource, visited map[NodeBaseInterface]bool) {
+	inputs, ok := node.(HasInputsInterface)
+	if !ok || visited[node] {
+		return
+	}
+	visited[node] = true
+
+	dataSources := inputs.GetDataSources()
+	for _, inputId := range slices.Sorted(maps.Keys(dataSources)) {
+		ds := dataSources[inputId]
+		src := ds.SrcNode
+		switch {
+		case isGroupPorts(src):
+			// values of group inputs are available as soon as the group is entered
+		case hasExecutionPorts(src):
+			if _, exists := producers[src]; !exists {
+				producers[src] = ds
+			}
+		default:
+			collectProducers(src, producers, visited)
+		}
+	}
+}
+
+// executionReachable returns true if `to` can be reached by following the execution
+// connections from `from`, without passing through `skip`.
+func executionReachable(from NodeBaseInterface, to NodeBaseInterface, skip NodeBaseInterface) bool {
+	visited := map[NodeBaseInterface]bool{from: true}
+	queue := []NodeBaseInterface{from}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		execNode, ok := node.(HasExecutionInterface)
+		if !ok {
+			continue
+		}
+		for _, target := range execNode.GetExecutions() {
+			if target.DstNode == nil || visited[target.DstNode] {
+				continue
+			}
+			if target.DstNode == to {
+				return true
+			}
+			visited[target.DstNode] = true
+			if target.DstNode != skip {
+				queue = append(queue, target.DstNode)
+			}
+		}
+	}
+	return false
+}
+
+// resolveOutputType returns the type of the value an output holds at runtime. For outputs
+// of type `unknown`, the type is derived from the input it depends on, if it's known.
+func resolveOutputType(ds DataSource, depth int) string {
+	typ, ok := outputType(ds)
+	if !ok || typ != "unknown" || depth > 32 {
+		return typ
+	}
+
+	rule, ok := unknownOutputs[ds.SrcNode.GetNodeTypeId()][OutputId(outputPortId(ds))]
+	if !ok {
+		return typ
+	}
+
+	inputs, ok := ds.SrcNode.(HasInputsInterface)
+	if !ok {
+		return typ
+	}
+	inputDs, ok := inputs.GetDataSources()[rule.input]
+	if !ok {
+		return typ
+	}
+
+	inputType := resolveOutputType(inputDs, depth+1)
+	if !rule.elem {
+		return inputType
+	}
+	if elemType, ok := strings.CutPrefix(inputType, "[]"); ok {
+		return elemType
+	}
+	return "unknown"
+}
+
+// outputType returns the declared type of the output of a data source.
+func outputType(ds DataSource) (string, bool) {
+	var (
+		portDef   PortDefinition
+		indexPort *IndexPortInfo
+	)
+
+	portId := string(ds.SrcOutputId)
+	if strings.HasPrefix(ds.SrcNode.GetNodeTypeId(), "core/group-inputs@") {
+		// the outputs of the group inputs are the inputs of the group node
+		groupNode, ok := ds.SrcNode.GetParent().(HasInputsInterface)
+		if !ok {
+			return "", false
+		}
+		inputDef, ip, ok := groupNode.InputDefByPortId(portId)
+		if !ok {
+			return "", false
+		}
+		portDef, indexPort = inputDef.PortDefinition, ip
+	} else {
+		outputDef, ip, ok := ds.SrcNodeOutputs.OutputDefByPortId(portId)
+		if !ok {
+			return "", false
+		}
+		portDef, indexPort = outputDef.PortDefinition, ip
+	}
+
+	if portDef.Array && indexPort == nil {
+		return "[]" + portDef.Type, true
+	}
+	return portDef.Type, true
+}
+
+// inputType returns the type of an input of a node, including index ports of array inputs.
+func inputType(node NodeBaseInterface, inputId InputId) (string, bool) {
+	var (
+		portDef   PortDefinition
+		indexPort *IndexPortInfo
+	)
+
+	if strings.HasPrefix(node.GetNodeTypeId(), "core/group-outputs@") {
+		// the inputs of the group outputs are the outputs of the group node
+		groupNode, ok := node.GetParent().(HasOutputsInterface)
+		if !ok {
+			return "", false
+		}
+		outputDef, ip, ok := groupNode.OutputDefByPortId(string(inputId))
+		if !ok {
+			return "", false
+		}
+		portDef, indexPort = outputDef.PortDefinition, ip
+	} else {
+		inputs, ok := node.(HasInputsInterface)
+		if !ok {
+			return "", false
+		}
+		inputDef, ip, ok := inputs.InputDefByPortId(string(inputId))
+		if !ok {
+			return "", false
+		}
+		portDef, indexPort = inputDef.PortDefinition, ip
+	}
+
+	if portDef.Array && indexPort == nil {
+		return "[]" + portDef.Type, true
+	}
+	return portDef.Type, true
+}
+
+func outputPortId(ds DataSource) string {
+	if ds.SrcIndexOutputInfo != nil {
+		return ds.SrcIndexOutputInfo.IndexPortId
+	}
+	return string(ds.SrcOutputId)
+}
+
+// isConcreteType returns false for types that only describe what kind of values a port accepts.
+func isConcreteType(typ string) bool {
+	switch strings.TrimPrefix(typ, "[]") {
+	case "", "unknown", "any", "iterable", "indexable":
+		return false
+	}
+	return true
+}
+
+func dataSourceNodes(node NodeBaseInterface) []NodeBaseInterface {
+	inputs, ok := node.(HasInputsInterface)
+	if !ok {
+		return nil
+	}
+
+	dataSources := inputs.GetDataSources()
+	nodes := make([]NodeBaseInterface, 0, len(dataSources))
+	for _, inputId := range slices.Sorted(maps.Keys(dataSources)) {
+		nodes = append(nodes, dataSources[inputId].SrcNode)
+	}
+	return nodes
+}
+
+// hasExecutionPorts returns true if a node has execution ports, whether they are connected or not.
+func hasExecutionPorts(node NodeBaseInterface) bool {
+	if inputs, ok := node.(HasInputsInterface); ok {
+		for _, inputDef := range inputs.GetInputDefs() {
+			if inputDef.Exec {
+				return true
+			}
+		}
+	}
+	if outputs, ok := node.(HasOutputsInterface); ok {
+		for _, outputDef := range outputs.OutputDefsClone() {
+			if outputDef.Exec {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isGroupPorts returns true for the group inputs and outputs nodes inside a group.
+func isGroupPorts(node NodeBaseInterface) bool {
+	nodeType := node.GetNodeTypeId()
+	return strings.HasPrefix(nodeType, "core/group-inputs@") || strings.HasPrefix(nodeType, "core/group-outputs@")
+}
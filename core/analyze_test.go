@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type analyzeTestNode struct {
+	NodeBaseComponent
+	Executions
+}
+
+func (n *analyzeTestNode) ExecuteImpl(c *ExecutionState, inputId InputId, prevError error) error {
+	return nil
+}
+
+func newAnalyzeTestNode(id string) *analyzeTestNode {
+	n := &analyzeTestNode{}
+	n.SetId(id)
+	n.Executions.Executions = map[OutputId]ExecutionTarget{}
+	return n
+}
+
+func TestExecutionReachable(t *testing.T) {
+	// start -> loop -[body]-> add
+	//               -[completed]-> print -> read
+	start := newAnalyzeTestNode("start")
+	loop := newAnalyzeTestNode("loop")
+	add := newAnalyzeTestNode("add")
+	print := newAnalyzeTestNode("print")
+	read := newAnalyzeTestNode("read")
+
+	start.Executions.Executions["exec"] = ExecutionTarget{SrcNode: start, DstNode: loop, Port: "exec"}
+	loop.Executions.Executions["exec-body"] = ExecutionTarget{SrcNode: loop, DstNode: add, Port: "exec"}
+	loop.Executions.Executions["exec-completed"] = ExecutionTarget{SrcNode: loop, DstNode: print, Port: "exec"}
+	print.Executions.Executions["exec"] = ExecutionTarget{SrcNode: print, DstNode: read, Port: "exec"}
+
+	assert.True(t, executionReachable(start, read, nil))
+	assert.False(t, executionReachable(read, print, nil))
+
+	// the loop body can run before the nodes after the loop
+	assert.True(t, executionReachable(start, add, print))
+
+	// 'read' is only executed after 'print'
+	assert.False(t, executionReachable(start, read, print))
+}
+
+func TestIsConcreteType(t *testing.T) {
+	for typ, concrete := range map[string]bool{
+		"string":    true,
+		"[]number":  true,
+		"stream":    true,
+		"":          false,
+		"unknown":   false,
+		"[]any":     false,
+		"iterable":  false,
+		"indexable": false,
+	} {
+		assert.Equal(t, concrete, isConcreteType(typ), typ)
+	}
+}
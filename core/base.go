@@ -130,6 +130,7 @@ func getExecNameRegex() *regexp.Regexp {
 type HasExecutionInterface interface {
 	Execute(outputPort OutputId, ec *ExecutionState, err error) error
 	GetExecutionTarget(outputId OutputId) (ExecutionTarget, bool)
+	GetExecutions() map[OutputId]ExecutionTarget
 	ExecuteImpl(c *ExecutionState, inputId InputId, prevError error) error
 	GetName() string
 	GetId() string
@@ -168,6 +169,14 @@ type NodeBaseInterface interface {
 	// Returns the cache type where data is stored or should be stored to
 	// By default this depends on if this is an execution node or not.
 	GetCacheType() CacheType
+
+	// The timeout and retry settings of the node, see `ExecutionPolicy`.
+	GetExecutionPolicy() ExecutionPolicy
+	SetExecutionPolicy(policy ExecutionPolicy)
+
+	// Whether the result of the node is cached across runs, see `MemoEntry`.
+	IsMemoized() bool
+	SetMemoized(memoize bool)
 }
 
 // Base component for nodes that offer values from other nodes.
@@ -181,6 +190,8 @@ type NodeBaseComponent struct {
 	NodeType        string // Node type of the node (e.g. core/run@v1 or github.com/actions/checkout@v3)
 	Graph           *ActionGraph
 	Parent          NodeBaseInterface
+	Policy          ExecutionPolicy
+	Memoize         bool
 	isExecutionNode bool
 }
 
@@ -200,6 +211,22 @@ func (n *NodeBaseComponent) SetExecutionNode(execNode bool) {
 	n.isExecutionNode = execNode
 }
 
+func (n *NodeBaseComponent) GetExecutionPolicy() ExecutionPolicy {
+	return n.Policy
+}
+
+func (n *NodeBaseComponent) SetExecutionPolicy(policy ExecutionPolicy) {
+	n.Policy = policy
+}
+
+func (n *NodeBaseComponent) IsMemoized() bool {
+	return n.Memoize
+}
+
+func (n *NodeBaseComponent) SetMemoized(memoize bool) {
+	n.Memoize = memoize
+}
+
 func (n *NodeBaseComponent) SetId(id string) {
 	n.Id = id
 	n.CacheId = fmt.Sprintf("%s:%s", n.Id, uuid.New().String())
@@ -314,6 +341,7 @@ type OutputDefinition struct {
 type InputDefinition struct {
 	PortDefinition `yaml:",inline" json:",inline" bson:",inline"`
 
+	// Inputs without a socket can't be connected, their value is always set in the node.
 	HideSocket bool `yaml:"hide_socket,omitempty" json:"hide_socket,omitempty" bson:"hide_socket,omitempty"`
 
 	// Default value used during execution if there is no connection nor a user input value.
@@ -581,21 +609,24 @@ func NewGhActionNode(nodeType string, parent NodeBaseInterface, parentId string,
 }
 
 func NewNodeInstance(nodeType string, parent NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (NodeBaseInterface, []error) {
+	return newNodeInstance(nil, nodeType, parent, parentId, nodeDef, validate)
+}
+
+func newNodeInstance(lc *GraphLoadContext, nodeType string, parent NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (NodeBaseInterface, []error) {
 	var (
 		node NodeBaseInterface
 		errs []error
 	)
 
 	// first versions of actionforge had no prefix `core/`
-	matched, _ := regexp.MatchString(`^[\w-]+@v1$`, nodeType)
-	if matched {
+	if legacyNodeTypeRegex.MatchString(nodeType) {
 		nodeType = "core/" + nodeType
 	}
 
 	factoryEntry, exists := registries[nodeType]
 	if exists {
 		// Pass 'validate' to the factory function
-		node, errs = factoryEntry.FactoryFn(nil, parent, parentId, nodeDef, validate)
+		node, errs = factoryEntry.FactoryFn(lc, parent, parentId, nodeDef, validate)
 		if len(errs) > 0 {
 			// If the factory failed to produce a node (or found errors), return them.
 			return nil, errs
@@ -47,7 +47,7 @@ const (
 // Nodes within these new executions can fetch their respective iteration index they are associated with.
 // Without this approach, all nodes in subsequent goroutines would fetch the same value, which is the last.
 //
-// Note that group nodes do *currently* NOT create a new execution state. I have added some more context why in `GroupNode`.
+// Note that group nodes only create a new execution state if they are isolated. I have added some more context why in `GroupNode`.
 type ExecutionState struct {
 	Graph *ActionGraph `json:"-"`
 	// The hierarchy slice represents the full stack of execution states from root to current.
@@ -70,6 +70,10 @@ type ExecutionState struct {
 	IsDebugSession   bool               `json:"isDebugMode"`
 	GraphFile        string             `json:"graphFile"`
 
+	// Whether a GitHub Actions runner parses the output of this run. False for GitHub
+	// workflows that run locally, where actrun handles the workflow commands alone.
+	IsGitHubRunner bool `json:"isGitHubRunner"`
+
 	Id      string            `json:"id"`
 	Env     map[string]string `json:"env"`
 	Inputs  map[string]any    `json:"inputs"`
@@ -82,11 +86,53 @@ type ExecutionState struct {
 	// this is the matrix for the current job, if provided
 	GhMatrix map[string]any `json:"ghMatrix"`
 
+	// The named semaphores of the run, shared by all execution states.
+	Semaphores *Semaphores `json:"-"`
+
+	// The steps that run after the graph finished, shared by all execution states.
+	PostSteps *PostSteps `json:"-"`
+
+	// The annotations reported with workflow commands, shared by all execution states.
+	Annotations *Annotations `json:"-"`
+
 	OutputCacheLock      *sync.RWMutex  `json:"-"`
 	DataOutputCache      map[string]any `json:"dataOutputCache"`
 	ExecutionOutputCache map[string]any `json:"executionOutputCache"`
 
 	DebugCallback DebugCallback `json:"-"`
+
+	// The journal of the run if it was started with a run directory.
+	// Only set for the root execution state.
+	Journal *RunJournal `json:"-"`
+
+	// The stream that run events are written to, if any.
+	Events *EventStream `json:"-"`
+
+	// The tracer that node spans are exported with, if any.
+	Tracer *Tracer `json:"-"`
+
+	// The directory that results of nodes with `cache: true` are stored in.
+	// If empty, the default cache directory of the user is used.
+	MemoDir string `json:"-"`
+
+	// The attempt of the node that currently runs under an execution policy, if any.
+	attempt *nodeAttempt
+
+	// The memo of the cached node that currently runs in this execution state, if any.
+	memo *nodeMemo
+
+	// The execution node whose `ExecuteImpl` currently runs in this execution state.
+	activeNode NodeBaseInterface
+
+	// The span of the active node for the event stream.
+	span *nodeSpan
+
+	// For the isolated groups that were left to this execution state, the execution state the
+	// group was left from, by group node. The outputs of a group are evaluated in that state.
+	groupStates map[NodeBaseInterface]*ExecutionState
+
+	// The streams of the run that are still open. See `trackedStream`.
+	streams *openStreams
 }
 
 type ExecutionStateOptions struct {
@@ -143,6 +189,7 @@ func (c *ExecutionState) PushNewExecutionState(parentNode NodeBaseInterface) *Ex
 		CtxCancel:        Cancel,
 		IsDebugSession:   c.IsDebugSession,
 		IsGitHubWorkflow: c.IsGitHubWorkflow,
+		IsGitHubRunner:   c.IsGitHubRunner,
 		GraphFile:        c.GraphFile,
 
 		Id:      uuid.New().String(),
@@ -150,12 +197,24 @@ func (c *ExecutionState) PushNewExecutionState(parentNode NodeBaseInterface) *Ex
 		Inputs:  c.Inputs,
 		Secrets: c.Secrets,
 
+		GhContext: c.GhContext,
+		GhNeeds:   c.GhNeeds,
+		GhMatrix:  c.GhMatrix,
+
+		Semaphores:  c.Semaphores,
+		PostSteps:   c.PostSteps,
+		Annotations: c.Annotations,
+		streams:     c.streams,
+
 		OutputCacheLock:      &sync.RWMutex{},
 		DataOutputCache:      make(map[string]any),
 		ExecutionOutputCache: make(map[string]any),
 
 		Visited:       visited,
 		DebugCallback: c.DebugCallback,
+		Events:        c.Events,
+		Tracer:        c.Tracer,
+		MemoDir:       c.MemoDir,
 	}
 
 	return newEc
@@ -255,6 +314,39 @@ func (c *ExecutionState) PopNodeVisit() {
 	}
 }
 
+func (c *ExecutionState) resumableNode(node NodeBaseInterface) (*JournalNode, bool) {
+	if c.Journal == nil {
+		return nil, false
+	}
+	return c.Journal.nodeToSkip(node)
+}
+
+// SetGroupState stores the execution state that an isolated group was left from. The state of
+// a previous execution of the group is replaced, so it's released once the group ran again.
+func (c *ExecutionState) SetGroupState(group NodeBaseInterface, inner *ExecutionState) {
+	c.ContextStackLock.Lock()
+	defer c.ContextStackLock.Unlock()
+
+	if c.groupStates == nil {
+		c.groupStates = make(map[NodeBaseInterface]*ExecutionState)
+	}
+	c.groupStates[group] = inner
+}
+
+// GroupState returns the execution state an isolated group was left from when it
+// was last left to `c` or one of its parent execution states.
+func (c *ExecutionState) GroupState(group NodeBaseInterface) *ExecutionState {
+	for ; c != nil; c = c.ParentExecution {
+		c.ContextStackLock.RLock()
+		inner, ok := c.groupStates[group]
+		c.ContextStackLock.RUnlock()
+		if ok {
+			return inner
+		}
+	}
+	return nil
+}
+
 // SetContextEnvironMap sets the environment variables for the current and subsequent goroutines.
 func (c *ExecutionState) SetContextEnvironMap(env map[string]string) {
 	c.ContextStackLock.Lock()
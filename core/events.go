@@ -0,0 +1,229 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RunEventType string
+
+const (
+	EventRunStarted   RunEventType = "run_started"
+	EventNodeEntered  RunEventType = "node_entered"
+	EventNodeFinished RunEventType = "node_finished"
+	EventOutputSet    RunEventType = "output_set"
+	EventError        RunEventType = "error"
+	EventRunFinished  RunEventType = "run_finished"
+)
+
+// RunEvent is a single line in the event stream of a run.
+//
+// Nodes in concurrent iterations run in their own execution state. `ExecutionId` and
+// `ParentExecutionId` tell them apart and link them to the state they were started from.
+type RunEvent struct {
+	Event             RunEventType `json:"event"`
+	Time              time.Time    `json:"time"`
+	RunId             string       `json:"run_id"`
+	ExecutionId       string       `json:"execution_id"`
+	ParentExecutionId string       `json:"parent_execution_id,omitempty"`
+
+	GraphFile string `json:"graph_file,omitempty"`
+
+	NodeId   string `json:"node_id,omitempty"`
+	FullPath string `json:"full_path,omitempty"`
+	NodeType string `json:"node_type,omitempty"`
+
+	// The execution input the node was entered through.
+	Port string `json:"port,omitempty"`
+	// The output the node continued with, or the output a value was set to.
+	Output     string  `json:"output,omitempty"`
+	OutputType string  `json:"output_type,omitempty"`
+	DurationMs float64 `json:"duration_ms,omitempty"`
+
+	Status JournalStatus `json:"status,omitempty"`
+	Error  string        `json:"error,omitempty"`
+	Hint   string        `json:"hint,omitempty"`
+}
+
+// EventStream writes the events of a run as JSON Lines.
+type EventStream struct {
+	lock    sync.Mutex
+	w       io.Writer
+	closer  io.Closer
+	enc     *json.Encoder
+	started time.Time
+	err     error
+}
+
+// nodeSpan is the time between a node being entered and the node
+// handing over to one of its execution outputs or returning.
+type nodeSpan struct {
+	node     NodeBaseInterface
+	started  time.Time
+	finished bool
+}
+
+// OpenEventStream opens the target of the `--events` flag. A plain number
+// is used as an already open file descriptor, anything else as a file path.
+func OpenEventStream(target string) (*EventStream, error) {
+	var f *os.File
+
+	fd, err := strconv.ParseUint(target, 10, 32)
+	if err == nil {
+		switch fd {
+		case 0, 1, 2:
+			// the standard streams stay open, errors of the run are printed after the events
+			return NewEventStream([]*os.File{os.Stdin, os.Stdout, os.Stderr}[fd], nil), nil
+		}
+		f = os.NewFile(uintptr(fd), "fd"+target)
+		_, err = f.Stat()
+		if err != nil {
+			return nil, CreateErr(nil, err, "file descriptor %d for events is not open", fd)
+		}
+	} else {
+		f, err = os.Create(target)
+		if err != nil {
+			return nil, CreateErr(nil, err, "failed to create events file '%s'", target)
+		}
+	}
+
+	return NewEventStream(f, f), nil
+}
+
+// NewEventStream creates an event stream that writes to `w`.
+// If `closer` is not nil, it is closed when the stream is closed.
+func NewEventStream(w io.Writer, closer io.Closer) *EventStream {
+	return &EventStream{
+		w:      w,
+		closer: closer,
+		enc:    json.NewEncoder(w),
+	}
+}
+
+func (s *EventStream) emit(ec *ExecutionState, ev RunEvent) {
+	ev.Time = time.Now()
+	ev.ExecutionId = ec.Id
+	if ec.ParentExecution != nil {
+		ev.ParentExecutionId = ec.ParentExecution.Id
+	}
+
+	root := ec
+	for root.ParentExecution != nil {
+		root = root.ParentExecution
+	}
+	ev.RunId = root.Id
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// A broken stream must not break the run, the error is reported once the run is over.
+	if s.err != nil {
+		return
+	}
+	s.err = s.enc.Encode(ev)
+}
+
+func (s *EventStream) runStarted(ec *ExecutionState) {
+	s.started = time.Now()
+	s.emit(ec, RunEvent{
+		Event:     EventRunStarted,
+		GraphFile: ec.GraphFile,
+	})
+}
+
+func (s *EventStream) runFinished(ec *ExecutionState, runErr error) {
+	if runErr != nil {
+		s.emit(ec, errorEvent(runErr))
+	}
+	s.emit(ec, RunEvent{
+		Event:      EventRunFinished,
+		GraphFile:  ec.GraphFile,
+		Status:     getRunStatus(ec, runErr),
+		DurationMs: durationMs(s.started),
+	})
+}
+
+func (s *EventStream) nodeEntered(ec *ExecutionState, node NodeBaseInterface, port InputId) *nodeSpan {
+	ev := nodeEvent(EventNodeEntered, node)
+	ev.Port = string(port)
+	s.emit(ec, ev)
+
+	return &nodeSpan{
+		node:    node,
+		started: time.Now(),
+	}
+}
+
+// nodeFinished is called when the node of the span hands over to `output`, or
+// when it returned without handing over, in which case `output` is empty.
+func (s *EventStream) nodeFinished(ec *ExecutionState, span *nodeSpan, output OutputId, err error) {
+	if span == nil || span.finished {
+		return
+	}
+	span.finished = true
+
+	ev := nodeEvent(EventNodeFinished, span.node)
+	ev.Output = string(output)
+	ev.DurationMs = durationMs(span.started)
+	ev.Status = JournalStatusSucceeded
+	// a loop signal ends the node as intended, it's not a failure
+	if err != nil && !IsLoopSignal(err) {
+		ev.Status = JournalStatusFailed
+		ev.Error = errorEvent(err).Error
+	}
+	s.emit(ec, ev)
+}
+
+func (s *EventStream) outputSet(ec *ExecutionState, node NodeBaseInterface, output OutputId, outputType string) {
+	ev := nodeEvent(EventOutputSet, node)
+	ev.Output = string(output)
+	ev.OutputType = outputType
+	s.emit(ec, ev)
+}
+
+// Close closes the underlying writer and returns the first error that occurred while writing.
+func (s *EventStream) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.err
+	if s.closer != nil {
+		err = errors.Join(err, s.closer.Close())
+	}
+	if err != nil {
+		return CreateErr(nil, err, "failed to write events")
+	}
+	return nil
+}
+
+func nodeEvent(event RunEventType, node NodeBaseInterface) RunEvent {
+	return RunEvent{
+		Event:    event,
+		NodeId:   node.GetId(),
+		FullPath: node.GetFullPath(),
+		NodeType: node.GetNodeTypeId(),
+	}
+}
+
+func errorEvent(err error) RunEvent {
+	ev := RunEvent{
+		Event: EventError,
+		Error: err.Error(),
+	}
+
+	var leafErr *LeafError
+	if errors.As(err, &leafErr) {
+		ev.Error = leafErr.ErrorWithCauses()
+		ev.Hint = getErrorHint(leafErr)
+	}
+	return ev
+}
+
+func durationMs(started time.Time) float64 {
+	return float64(time.Since(started).Microseconds()) / 1000
+}
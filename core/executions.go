@@ -1,6 +1,10 @@
 package core
 
-import "github.com/actionforge/actrun-cli/utils"
+import (
+	"errors"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
 
 type ExecutionSource struct {
 	SrcNode HasExecutionInterface
@@ -24,6 +28,16 @@ type Executions struct {
 
 func (n *Executions) Execute(outputPort OutputId, ec *ExecutionState, err error) error {
 
+	// A node running under an execution policy hands over to the next node here.
+	// From now on, failures are no longer the concern of its timeout or retries.
+	if a := ec.attempt; a != nil && !a.handedOff {
+		err = a.handOver(ec, err)
+		var retryErr *errRetryNode
+		if errors.As(err, &retryErr) {
+			return err
+		}
+	}
+
 	// Inbetween the execution of nodes we need to reset the ephemeral data output cache.
 	// Every execution node receives a fresh batch of data from its incoming connections.
 	ec.EmptyDataOutputCache()
@@ -39,6 +53,26 @@ func (n *Executions) Execute(outputPort OutputId, ec *ExecutionState, err error)
 		}
 	}
 
+	// The node completed, unless it failed with an unhandled error above.
+	if m := ec.memo; m != nil && m.node == ec.activeNode {
+		m.store(ec, outputPort, err)
+		ec.memo = nil
+	}
+
+	if ec.Journal != nil && ec.activeNode != nil {
+		journalErr := ec.Journal.recordHandOver(ec, ec.activeNode, outputPort, err)
+		if journalErr != nil {
+			return journalErr
+		}
+	}
+
+	if ec.Events != nil {
+		ec.Events.nodeFinished(ec, ec.span, outputPort, err)
+	}
+	if ec.Tracer != nil {
+		ec.Tracer.nodeFinished(ec, ec.activeNode, outputPort, err)
+	}
+
 	// nothing to execute
 	if !hasDest || dest.DstNode == nil {
 		return nil
@@ -53,7 +87,40 @@ func (n *Executions) Execute(outputPort OutputId, ec *ExecutionState, err error)
 		return nil
 	}
 
-	err = dest.DstNode.ExecuteImpl(ec, dest.Port, err)
+	prevNode, prevSpan := ec.activeNode, ec.span
+	ec.activeNode = dest.DstNode
+	if ec.Events != nil {
+		ec.span = ec.Events.nodeEntered(ec, dest.DstNode, dest.Port)
+	}
+	if ec.Tracer != nil {
+		ec.Tracer.nodeEntered(ec, dest.DstNode, dest.Port)
+	}
+
+	policy := dest.DstNode.GetExecutionPolicy()
+	if jn, skip := ec.resumableNode(dest.DstNode); skip {
+		err = ec.Journal.skipNode(ec, dest.DstNode, jn)
+	} else if memo := ec.lookupMemo(dest.DstNode); memo != nil && memo.hit != nil {
+		err = memo.restore(ec, dest.DstNode)
+	} else {
+		prevMemo := ec.memo
+		ec.memo = memo
+		if policy.IsZero() {
+			err = dest.DstNode.ExecuteImpl(ec, dest.Port, err)
+		} else {
+			err = executeWithPolicy(ec, dest.DstNode, dest.Port, err, policy)
+		}
+		ec.memo = prevMemo
+	}
+
+	if ec.Events != nil {
+		// the node returned without handing over, eg. on an unhandled error
+		ec.Events.nodeFinished(ec, ec.span, "", err)
+	}
+	if ec.Tracer != nil {
+		ec.Tracer.nodeFinished(ec, dest.DstNode, "", err)
+	}
+
+	ec.activeNode, ec.span = prevNode, prevSpan
 	if err != nil {
 		return err
 	}
@@ -61,6 +128,10 @@ func (n *Executions) Execute(outputPort OutputId, ec *ExecutionState, err error)
 	return nil
 }
 
+func (e *Executions) GetExecutions() map[OutputId]ExecutionTarget {
+	return e.Executions
+}
+
 func (e *Executions) GetExecutionTarget(outputId OutputId) (ExecutionTarget, bool) {
 	t, ok := e.Executions[OutputId(outputId)]
 	return t, ok
@@ -0,0 +1,415 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// ExportFormats are the formats a graph can be exported to with `ExportGraph`.
+var ExportFormats = []string{"dot", "mermaid", "svg"}
+
+// exportPort is an input or output of a node as it's shown in an exported graph.
+type exportPort struct {
+	id    string
+	label string
+}
+
+// exportEdge is an execution or data connection between two nodes.
+type exportEdge struct {
+	src     NodeBaseInterface
+	srcPort string
+	dst     NodeBaseInterface
+	dstPort string
+	exec    bool
+}
+
+// graphExport collects the nodes and connections of a graph and its groups.
+type graphExport struct {
+	// The graph level each node belongs to.
+	levels map[NodeBaseInterface]*ActionGraph
+	edges  []exportEdge
+}
+
+// ExportGraph writes a graph in one of the `ExportFormats` to `w`. Execution connections are
+// drawn as solid lines, data connections as dashed lines and groups as clusters of their nodes.
+// The svg format requires the `dot` command of Graphviz.
+func ExportGraph(w io.Writer, graphName string, ag *ActionGraph, format string) error {
+	e := &graphExport{
+		levels: map[NodeBaseInterface]*ActionGraph{},
+	}
+	e.collectLevels(ag)
+	e.collectEdges(ag)
+
+	switch format {
+	case "dot":
+		e.writeDot(w, graphName, ag)
+		return nil
+	case "mermaid":
+		e.writeMermaid(w, ag)
+		return nil
+	case "svg":
+		var dot bytes.Buffer
+		e.writeDot(&dot, graphName, ag)
+		return renderSvg(w, &dot)
+	default:
+		return CreateErr(nil, nil, "unknown export format '%s'", format).
+			SetHint("use one of: %s", strings.Join(ExportFormats, ", "))
+	}
+}
+
+// ExportGraphFromFile loads a graph file and writes it in the given format to `w`.
+func ExportGraphFromFile(graphFile string, format string, w io.Writer) error {
+	graphContent, err := os.ReadFile(graphFile)
+	if err != nil {
+		return CreateErr(nil, err, "failed loading graph")
+	}
+
+	graphYaml := make(map[string]any)
+	err = yaml.Unmarshal(graphContent, &graphYaml)
+	if err != nil {
+		return CreateErr(nil, err, "failed to load yaml")
+	}
+
+	ag, errs := LoadGraph(graphYaml, nil, "", GraphLoadContext{Dir: filepath.Dir(graphFile)}, false)
+	if len(errs) > 0 {
+		return CreateErr(nil, errs[0], "failed to load graph")
+	}
+
+	return ExportGraph(w, graphFile, &ag, format)
+}
+
+func renderSvg(w io.Writer, dot io.Reader) error {
+	dotPath, err := exec.LookPath("dot")
+	if err != nil {
+		return CreateErr(nil, err, "failed to render svg").
+			SetHint("install Graphviz, or export the graph with '--format dot' and render it elsewhere")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(dotPath, "-Tsvg")
+	cmd.Stdin = dot
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return CreateErr(nil, err, "failed to render svg: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (e *graphExport) collectLevels(ag *ActionGraph) {
+	for _, node := range ag.Nodes {
+		e.levels[node] = ag
+		if isExportCluster(node) {
+			e.collectLevels(node.GetGraph())
+		}
+	}
+}
+
+// collectEdges collects the connections between the nodes of each graph level. The connections
+// between a group node and its group inputs and outputs are implicit, and connections to the
+// ports of a group node are drawn to the group inputs or outputs inside its cluster instead.
+func (e *graphExport) collectEdges(ag *ActionGraph) {
+	for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+		node := ag.Nodes[nodeId]
+
+		if execNode, ok := node.(HasExecutionInterface); ok {
+			executions := execNode.GetExecutions()
+			for _, outputId := range slices.Sorted(maps.Keys(executions)) {
+				target := executions[outputId]
+				if target.DstNode != nil {
+					e.addEdge(exportEdge{src: node, srcPort: string(outputId), dst: target.DstNode, dstPort: string(target.Port), exec: true})
+				}
+			}
+		}
+
+		if inputs, ok := node.(HasInputsInterface); ok {
+			dataSources := inputs.GetDataSources()
+			for _, inputId := range slices.Sorted(maps.Keys(dataSources)) {
+				ds := dataSources[inputId]
+				e.addEdge(exportEdge{src: ds.SrcNode, srcPort: outputPortId(ds), dst: node, dstPort: string(inputId)})
+			}
+		}
+
+		if isExportCluster(node) {
+			e.collectEdges(node.GetGraph())
+		}
+	}
+}
+
+func (e *graphExport) addEdge(edge exportEdge) {
+	if e.levels[edge.src] != e.levels[edge.dst] {
+		return
+	}
+	if isExportCluster(edge.src) {
+		edge.src = groupPortsNode(edge.src.GetGraph(), "core/group-outputs@")
+	}
+	if isExportCluster(edge.dst) {
+		edge.dst = groupPortsNode(edge.dst.GetGraph(), "core/group-inputs@")
+	}
+	if edge.src != nil && edge.dst != nil {
+		e.edges = append(e.edges, edge)
+	}
+}
+
+func (e *graphExport) writeDot(w io.Writer, graphName string, ag *ActionGraph) {
+	fmt.Fprintf(w, "digraph %s {\n", dotQuote(graphName))
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=plaintext, fontname=\"Helvetica\", fontsize=10];")
+	fmt.Fprintln(w, "  edge [fontname=\"Helvetica\", fontsize=9];")
+
+	clusters := 0
+	var writeLevel func(ag *ActionGraph, indent string)
+	writeLevel = func(ag *ActionGraph, indent string) {
+		for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+			node := ag.Nodes[nodeId]
+
+			if isExportCluster(node) {
+				fmt.Fprintf(w, "%ssubgraph cluster_%d {\n", indent, clusters)
+				clusters++
+				fmt.Fprintf(w, "%s  label=%s;\n", indent, dotQuote(fmt.Sprintf("%s (%s)", exportNodeName(node), node.GetId())))
+				fmt.Fprintf(w, "%s  style=rounded;\n", indent)
+				writeLevel(node.GetGraph(), indent+"  ")
+				fmt.Fprintf(w, "%s}\n", indent)
+				continue
+			}
+
+			fmt.Fprintf(w, "%s%s [label=<%s>];\n", indent, dotQuote(node.GetFullPath()), dotNodeLabel(node))
+		}
+	}
+	writeLevel(ag, "  ")
+
+	for _, edge := range e.edges {
+		style := "style=dashed, color=\"#6b7b8c\""
+		if edge.exec {
+			style = "penwidth=2"
+		}
+		fmt.Fprintf(w, "  %s:%s:e -> %s:%s:w [%s];\n",
+			dotQuote(edge.src.GetFullPath()), dotQuote("o_"+edge.srcPort),
+			dotQuote(edge.dst.GetFullPath()), dotQuote("i_"+edge.dstPort),
+			style)
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+func (e *graphExport) writeMermaid(w io.Writer, ag *ActionGraph) {
+	fmt.Fprintln(w, "flowchart LR")
+
+	ids := map[NodeBaseInterface]string{}
+	clusters := 0
+	var writeLevel func(ag *ActionGraph, indent string)
+	writeLevel = func(ag *ActionGraph, indent string) {
+		for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+			node := ag.Nodes[nodeId]
+
+			if isExportCluster(node) {
+				fmt.Fprintf(w, "%ssubgraph g%d[%s]\n", indent, clusters, mermaidQuote(fmt.Sprintf("%s (%s)", exportNodeName(node), node.GetId())))
+				clusters++
+				writeLevel(node.GetGraph(), indent+"  ")
+				fmt.Fprintf(w, "%send\n", indent)
+				continue
+			}
+
+			id := fmt.Sprintf("n%d", len(ids))
+			ids[node] = id
+			fmt.Fprintf(w, "%s%s[%s]\n", indent, id, mermaidQuote(fmt.Sprintf("<b>%s</b><br/>%s", html.EscapeString(exportNodeName(node)), node.GetNodeTypeId())))
+		}
+	}
+	writeLevel(ag, "  ")
+
+	// mermaid has no ports, so the ports are shown as labels of the connections
+	for _, edge := range e.edges {
+		label := fmt.Sprintf("%s → %s", portLabel(edge.src, edge.srcPort, false), portLabel(edge.dst, edge.dstPort, true))
+		arrow := "-.->"
+		if edge.exec {
+			arrow = "==>"
+		}
+		fmt.Fprintf(w, "  %s %s|%s| %s\n", ids[edge.src], arrow, mermaidQuote(label), ids[edge.dst])
+	}
+}
+
+// dotNodeLabel returns an HTML-like label of a node with its inputs on the left and its outputs on the right.
+func dotNodeLabel(node NodeBaseInterface) string {
+	inputs, outputs := exportPorts(node)
+
+	var b strings.Builder
+	b.WriteString(`<table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+	fmt.Fprintf(&b, `<tr><td colspan="2" bgcolor="#dde3ea"><b>%s</b><br/><font point-size="8">%s</font></td></tr>`,
+		html.EscapeString(exportNodeName(node)), html.EscapeString(node.GetNodeTypeId()))
+
+	for i := range max(len(inputs), len(outputs)) {
+		b.WriteString("<tr>")
+		if i < len(inputs) {
+			fmt.Fprintf(&b, `<td port="i_%s" align="left">%s</td>`, html.EscapeString(inputs[i].id), html.EscapeString(inputs[i].label))
+		} else {
+			b.WriteString(`<td border="0"></td>`)
+		}
+		if i < len(outputs) {
+			fmt.Fprintf(&b, `<td port="o_%s" align="right">%s</td>`, html.EscapeString(outputs[i].id), html.EscapeString(outputs[i].label))
+		} else {
+			b.WriteString(`<td border="0"></td>`)
+		}
+		b.WriteString("</tr>")
+	}
+
+	b.WriteString("</table>")
+	return b.String()
+}
+
+// exportPorts returns the inputs and outputs of a node in the order of their port definitions.
+// Array ports are listed by their index ports if they have any.
+func exportPorts(node NodeBaseInterface) ([]exportPort, []exportPort) {
+	var (
+		inputDefs        map[string]PortDefinition
+		outputDefs       map[string]PortDefinition
+		inputIndexPorts  map[string]IndexPortInfo
+		outputIndexPorts map[string]IndexPortInfo
+	)
+
+	// the group inputs and outputs have the ports on both sides, since they are connected
+	// to the nodes around the cluster of their group and to the nodes inside of it
+	switch {
+	case strings.HasPrefix(node.GetNodeTypeId(), "core/group-inputs@"):
+		if group, ok := node.GetParent().(HasInputsInterface); ok {
+			inputDefs, inputIndexPorts = inputPortDefs(group)
+			outputDefs, outputIndexPorts = inputDefs, inputIndexPorts
+		}
+	case strings.HasPrefix(node.GetNodeTypeId(), "core/group-outputs@"):
+		if group, ok := node.GetParent().(HasOutputsInterface); ok {
+			outputDefs, outputIndexPorts = outputPortDefs(group)
+			inputDefs, inputIndexPorts = outputDefs, outputIndexPorts
+		}
+	default:
+		if inputs, ok := node.(HasInputsInterface); ok {
+			inputDefs, inputIndexPorts = inputPortDefs(inputs)
+		}
+		if outputs, ok := node.(HasOutputsInterface); ok {
+			outputDefs, outputIndexPorts = outputPortDefs(outputs)
+		}
+	}
+
+	return sortedExportPorts(inputDefs, inputIndexPorts), sortedExportPorts(outputDefs, outputIndexPorts)
+}
+
+func inputPortDefs(inputs HasInputsInterface) (map[string]PortDefinition, map[string]IndexPortInfo) {
+	defs := map[string]PortDefinition{}
+	for id, def := range inputs.GetInputDefs() {
+		defs[string(id)] = def.PortDefinition
+	}
+	return defs, inputs.GetInputIndexPorts()
+}
+
+func outputPortDefs(outputs HasOutputsInterface) (map[string]PortDefinition, map[string]IndexPortInfo) {
+	defs := map[string]PortDefinition{}
+	for id, def := range outputs.OutputDefsClone() {
+		defs[string(id)] = def.PortDefinition
+	}
+	return defs, outputs.GetOutputIndexPorts()
+}
+
+func sortedExportPorts(defs map[string]PortDefinition, indexPorts map[string]IndexPortInfo) []exportPort {
+	ids := slices.SortedFunc(maps.Keys(defs), func(a, b string) int {
+		if defs[a].Index != defs[b].Index {
+			return defs[a].Index - defs[b].Index
+		}
+		return strings.Compare(a, b)
+	})
+
+	var ports []exportPort
+	for _, id := range ids {
+		def := defs[id]
+
+		var subPorts []IndexPortInfo
+		for _, indexPort := range indexPorts {
+			if indexPort.ArrayPortId == id {
+				subPorts = append(subPorts, indexPort)
+			}
+		}
+		slices.SortFunc(subPorts, func(a, b IndexPortInfo) int {
+			return a.Index - b.Index
+		})
+
+		if len(subPorts) == 0 {
+			ports = append(ports, exportPort{id: id, label: formatPortLabel(id, def, def.Array)})
+			continue
+		}
+		for _, indexPort := range subPorts {
+			ports = append(ports, exportPort{id: indexPort.IndexPortId, label: formatPortLabel(indexPort.IndexPortId, def, false)})
+		}
+	}
+	return ports
+}
+
+// formatPortLabel returns the name and type of a port, e.g. 'Path: string'. Execution ports only have their name.
+func formatPortLabel(id string, def PortDefinition, array bool) string {
+	name := def.Name
+	if name == "" || strings.Contains(id, "[") {
+		name = id
+	}
+	if def.Exec {
+		return "▶ " + name
+	}
+	typ := def.Type
+	if array {
+		typ = "[]" + typ
+	}
+	return fmt.Sprintf("%s: %s", name, typ)
+}
+
+// portLabel returns the label of an input or output of a node, or the id of the port if it isn't defined.
+func portLabel(node NodeBaseInterface, portId string, input bool) string {
+	inputs, outputs := exportPorts(node)
+	ports := outputs
+	if input {
+		ports = inputs
+	}
+	for _, p := range ports {
+		if p.id == portId {
+			return p.label
+		}
+	}
+	return portId
+}
+
+func exportNodeName(node NodeBaseInterface) string {
+	if node.GetLabel() != "" {
+		return node.GetLabel()
+	}
+	if node.GetName() != "" {
+		return node.GetName()
+	}
+	return node.GetId()
+}
+
+// isExportCluster returns true for group nodes, which are drawn as a cluster of the nodes of their graph.
+func isExportCluster(node NodeBaseInterface) bool {
+	return isGroupNode(node) && node.GetGraph() != nil
+}
+
+func groupPortsNode(ag *ActionGraph, nodeType string) NodeBaseInterface {
+	for _, node := range ag.Nodes {
+		if strings.HasPrefix(node.GetNodeTypeId(), nodeType) {
+			return node
+		}
+	}
+	return nil
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
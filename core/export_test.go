@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedExportPorts(t *testing.T) {
+	defs := map[string]PortDefinition{
+		"exec":   {Exec: true, Index: 0},
+		"values": {Name: "Values", Type: "string", Array: true, Index: 2},
+		"path":   {Name: "Path", Type: "string", Index: 1},
+		"args":   {Name: "Args", Type: "string", Array: true, Index: 3},
+	}
+	indexPorts := map[string]IndexPortInfo{
+		"values[1]": {IndexPortId: "values[1]", ArrayPortId: "values", Index: 1},
+		"values[0]": {IndexPortId: "values[0]", ArrayPortId: "values", Index: 0},
+	}
+
+	assert.Equal(t, []exportPort{
+		{id: "exec", label: "▶ exec"},
+		{id: "path", label: "Path: string"},
+		{id: "values[0]", label: "values[0]: string"},
+		{id: "values[1]", label: "values[1]: string"},
+		{id: "args", label: "Args: []string"},
+	}, sortedExportPorts(defs, indexPorts))
+}
+
+func TestExportQuote(t *testing.T) {
+	assert.Equal(t, `"say \"hi\"\n"`, dotQuote("say \"hi\"\n"))
+	assert.Equal(t, `"say #quot;hi#quot;"`, mermaidQuote(`say "hi"`))
+}
@@ -0,0 +1,337 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+// GhEmulateOpts configures the emulation of a GitHub Actions runner. See `EmulateGitHubRunner`.
+type GhEmulateOpts struct {
+	// Name of the event that triggered the workflow, e.g. 'push'.
+	Event string
+	// JSON file with the webhook payload of the event. If empty, a minimal payload is created.
+	EventPayload string
+}
+
+// EmulateGitHubRunner sets up the environment a GitHub Actions runner provides to a job,
+// so graphs that start with `core/gh-start@v1` can run locally. The runner directories
+// are created in a temp dir, the `github` context is derived from the git repository
+// of the current working directory. For pull request events, the pull request merges
+// the checked out branch into the default branch. Like values of .env files, variables
+// that are already set in the shell take precedence, except for the event name and payload.
+// The run must set `RunOpts.GhEmulate`, since no runner parses its output.
+// The returned function removes the temp dir.
+func EmulateGitHubRunner(opts GhEmulateOpts) (func(), error) {
+	workspace, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, CreateErr(nil, err, "unable to emulate GitHub Actions, the current directory is not in a git repository").
+			SetHint("Run actrun with --gh_emulate inside the git repository that the workflow is for.")
+	}
+
+	sha, err := gitOutput("rev-parse", "HEAD")
+	if err != nil {
+		return nil, CreateErr(nil, err, "unable to emulate GitHub Actions, the git repository has no commits")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "actrun-gh-")
+	if err != nil {
+		return nil, CreateErr(nil, err, "unable to create temp dir for the runner")
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(tmpDir)
+	}
+
+	runnerTemp := filepath.Join(tmpDir, "temp")
+	toolCache := filepath.Join(tmpDir, "tool_cache")
+	for _, dir := range []string{
+		filepath.Join(runnerTemp, "_github_home"),
+		filepath.Join(runnerTemp, "_github_workflow"),
+		toolCache,
+	} {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			cleanup()
+			return nil, CreateErr(nil, err, "unable to create runner directory '%s'", dir)
+		}
+	}
+
+	ref, refName, refType := gitRef()
+	actor := gitActor()
+	owner, repo := parseGitHubRepository(gitRemoteUrl())
+	if repo == "" {
+		owner, repo = actor, filepath.Base(workspace)
+	}
+	repository := owner + "/" + repo
+
+	event := utils.If(opts.Event != "", opts.Event, "push")
+	eventPath := filepath.Join(runnerTemp, "_github_workflow", "event.json")
+	var payload map[string]any
+	if opts.EventPayload != "" {
+		eventPath, err = filepath.Abs(opts.EventPayload)
+		if err == nil {
+			payload, err = readEventPayload(eventPath)
+		}
+		if err != nil {
+			cleanup()
+			return nil, CreateErr(nil, err, "unable to read event payload '%s'", opts.EventPayload)
+		}
+	}
+
+	// a pull request runs on the merge ref of the pull request, or for
+	// `pull_request_target` on the base branch, and sets the head and base refs
+	var pr *emulatedPullRequest
+	if event == "pull_request" || event == "pull_request_target" {
+		pr = newEmulatedPullRequest(payload, refName)
+		if event == "pull_request" {
+			ref, refName = fmt.Sprintf("refs/pull/%d/merge", pr.number), fmt.Sprintf("%d/merge", pr.number)
+		} else {
+			ref, refName = "refs/heads/"+pr.baseRef, pr.baseRef
+		}
+		refType = "branch"
+	}
+
+	if opts.EventPayload == "" {
+		err = writeEventPayload(eventPath, event, ref, sha, owner, repo, actor, pr)
+		if err != nil {
+			cleanup()
+			return nil, CreateErr(nil, err, "unable to write event payload")
+		}
+	}
+
+	env := map[string]string{
+		"CI":                      "true",
+		"GITHUB_ACTIONS":          "true",
+		"GITHUB_ACTOR":            actor,
+		"GITHUB_API_URL":          "https://api.github.com",
+		"GITHUB_GRAPHQL_URL":      "https://api.github.com/graphql",
+		"GITHUB_JOB":              "local",
+		"GITHUB_REF":              ref,
+		"GITHUB_REF_NAME":         refName,
+		"GITHUB_REF_TYPE":         refType,
+		"GITHUB_REPOSITORY":       repository,
+		"GITHUB_REPOSITORY_OWNER": owner,
+		"GITHUB_RUN_ATTEMPT":      "1",
+		"GITHUB_RUN_ID":           "1",
+		"GITHUB_RUN_NUMBER":       "1",
+		"GITHUB_SERVER_URL":       "https://github.com",
+		"GITHUB_SHA":              sha,
+		"GITHUB_WORKFLOW":         "local",
+		"GITHUB_WORKSPACE":        workspace,
+		"RUNNER_ARCH":             runnerArch(),
+		"RUNNER_NAME":             "actrun",
+		"RUNNER_OS":               runnerOs(),
+		"RUNNER_TEMP":             runnerTemp,
+		"RUNNER_TOOL_CACHE":       toolCache,
+	}
+	if pr != nil {
+		env["GITHUB_HEAD_REF"] = pr.headRef
+		env["GITHUB_BASE_REF"] = pr.baseRef
+	}
+	for k, v := range env {
+		if _, exists := os.LookupEnv(k); !exists {
+			_ = os.Setenv(k, v)
+		}
+	}
+
+	// the event is chosen on the command line, so it replaces the one of the shell
+	_ = os.Setenv("GITHUB_EVENT_NAME", event)
+	_ = os.Setenv("GITHUB_EVENT_PATH", eventPath)
+
+	utils.LogOut.Debugf("emulating GitHub Actions runner for event '%s' on %s (%s)\n", event, repository, ref)
+
+	return cleanup, nil
+}
+
+// emulatedPullRequest is the pull request of a `pull_request` or `pull_request_target` event.
+type emulatedPullRequest struct {
+	number  int
+	title   string
+	headRef string
+	baseRef string
+}
+
+// newEmulatedPullRequest returns the pull request of the event payload. Properties that the
+// payload doesn't have are derived from the git repository: the pull request merges the
+// checked out branch into the default branch of 'origin'.
+func newEmulatedPullRequest(payload map[string]any, branch string) *emulatedPullRequest {
+	pr := &emulatedPullRequest{
+		number:  1,
+		headRef: branch,
+		baseRef: gitDefaultBranch(),
+	}
+	pr.title, _ = gitOutput("log", "-1", "--format=%s")
+
+	prPayload, _ := payload["pull_request"].(map[string]any)
+	if number, ok := prPayload["number"].(float64); ok {
+		pr.number = int(number)
+	}
+	if title, ok := prPayload["title"].(string); ok {
+		pr.title = title
+	}
+	head, _ := prPayload["head"].(map[string]any)
+	if ref, ok := head["ref"].(string); ok {
+		pr.headRef = ref
+	}
+	base, _ := prPayload["base"].(map[string]any)
+	if ref, ok := base["ref"].(string); ok {
+		pr.baseRef = ref
+	}
+	return pr
+}
+
+func readEventPayload(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]any
+	err = json.Unmarshal(data, &payload)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// writeEventPayload writes a minimal webhook payload of the event with the
+// properties that workflows commonly access.
+func writeEventPayload(path string, event string, ref string, sha string, owner string, repo string, actor string, pr *emulatedPullRequest) error {
+	repository := map[string]any{
+		"name":      repo,
+		"full_name": owner + "/" + repo,
+		"owner": map[string]any{
+			"login": owner,
+		},
+	}
+	payload := map[string]any{
+		"repository": repository,
+		"sender": map[string]any{
+			"login": actor,
+		},
+	}
+	switch {
+	case event == "push":
+		payload["ref"] = ref
+		payload["after"] = sha
+		payload["head_commit"] = map[string]any{
+			"id": sha,
+		}
+	case pr != nil:
+		payload["action"] = "opened"
+		payload["number"] = pr.number
+		payload["pull_request"] = map[string]any{
+			"number": pr.number,
+			"title":  pr.title,
+			"state":  "open",
+			"user": map[string]any{
+				"login": actor,
+			},
+			"head": map[string]any{
+				"ref":  pr.headRef,
+				"sha":  sha,
+				"repo": repository,
+			},
+			"base": map[string]any{
+				"ref":  pr.baseRef,
+				"repo": repository,
+			},
+		}
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// gitRef returns the ref that is checked out, its short name and whether it's a branch or a tag.
+func gitRef() (string, string, string) {
+	branch, err := gitOutput("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err == nil {
+		return "refs/heads/" + branch, branch, "branch"
+	}
+	tag, err := gitOutput("describe", "--tags", "--exact-match", "HEAD")
+	if err == nil {
+		return "refs/tags/" + tag, tag, "tag"
+	}
+	return "", "", ""
+}
+
+// gitDefaultBranch returns the default branch of 'origin', or 'main' if it's unknown.
+func gitDefaultBranch() string {
+	branch, err := gitOutput("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if err == nil && branch != "" {
+		return strings.TrimPrefix(branch, "origin/")
+	}
+	return "main"
+}
+
+func gitActor() string {
+	name, err := gitOutput("config", "user.name")
+	if err == nil && name != "" {
+		return name
+	}
+	u, err := user.Current()
+	if err == nil {
+		return u.Username
+	}
+	return "actrun"
+}
+
+func gitRemoteUrl() string {
+	url, _ := gitOutput("remote", "get-url", "origin")
+	return url
+}
+
+// parseGitHubRepository returns the owner and name of the repository of a remote url like
+// 'https://github.com/owner/repo.git' or 'git@github.com:owner/repo.git'.
+func parseGitHubRepository(remoteUrl string) (string, string) {
+	remoteUrl = strings.TrimSuffix(strings.TrimSuffix(remoteUrl, "/"), ".git")
+	parts := strings.FieldsFunc(remoteUrl, func(r rune) bool {
+		return r == '/' || r == ':'
+	})
+	if len(parts) < 3 {
+		return "", ""
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+func gitOutput(args ...string) (string, error) {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func runnerOs() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "macOS"
+	case "windows":
+		return "Windows"
+	default:
+		return "Linux"
+	}
+}
+
+func runnerArch() string {
+	switch runtime.GOARCH {
+	case "arm64":
+		return "ARM64"
+	case "arm":
+		return "ARM"
+	case "386":
+		return "X86"
+	default:
+		return "X64"
+	}
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitHubRepository(t *testing.T) {
+	for remoteUrl, expected := range map[string][2]string{
+		"https://github.com/actionforge/actrun-cli.git": {"actionforge", "actrun-cli"},
+		"https://github.com/actionforge/actrun-cli/":    {"actionforge", "actrun-cli"},
+		"git@github.com:actionforge/actrun-cli.git":     {"actionforge", "actrun-cli"},
+		"ssh://git@github.com/actionforge/actrun-cli":   {"actionforge", "actrun-cli"},
+		"git@github.com:actrun-cli.git":                 {"", ""},
+		"":                                              {"", ""},
+	} {
+		owner, repo := parseGitHubRepository(remoteUrl)
+		assert.Equal(t, expected, [2]string{owner, repo}, remoteUrl)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"os/exec"
@@ -48,18 +49,21 @@ func SplitAtCommas(s string) []string {
 	return append(res, s[beg:])
 }
 
-func ExecuteDockerCommand(ctx context.Context, command string, optionsString string, workdir string, stdoutDataReceived chan string, stderrDataReceived chan string) (int, error) {
+// ExecuteDockerCommand runs a docker command. The output is written to `stdout` and
+// `stderr`, or to the logs if they are nil.
+func ExecuteDockerCommand(ctx context.Context, command string, optionsString string, workdir string, stdout io.Writer, stderr io.Writer) (int, error) {
 	args, err := shlex.Split(optionsString)
 	if err != nil {
 		return 1, err
 	}
 	cmdArgs := append([]string{command}, args...)
 
-	cmd := exec.Command("docker", cmdArgs...)
-	cmd.Stdout = utils.LogOut.Out
-	cmd.Stderr = utils.LogErr.Out
+	cmd := exec.CommandContext(ctx, "docker", cmdArgs...)
+	utils.SetupProcessGroup(cmd)
+	cmd.Stdout = utils.If(stdout != nil, stdout, utils.LogOut.Out)
+	cmd.Stderr = utils.If(stderr != nil, stderr, utils.LogErr.Out)
 	cmd.Dir = workdir
-	err = cmd.Run()
+	err = utils.RunProcess(cmd)
 	exitCode := 0
 	if err != nil {
 		exitError, ok := err.(*exec.ExitError)
@@ -96,7 +100,7 @@ func SanitizeOptionKeyValue(value string) string {
 	return value
 }
 
-func DockerRun(ctx context.Context, label string, container ContainerInfo, workingDirectory string, stdoutDataReceived, stderrDataReceived chan string) (int, error) {
+func DockerRun(ctx context.Context, label string, container ContainerInfo, workingDirectory string, stdout, stderr io.Writer) (int, error) {
 	var dockerOptions []string
 
 	dockerOptions = append(dockerOptions,
@@ -133,7 +137,7 @@ func DockerRun(ctx context.Context, label string, container ContainerInfo, worki
 	dockerOptions = append(dockerOptions, container.ContainerEntryPointArgs)
 
 	optionsString := strings.Join(dockerOptions, " ")
-	return ExecuteDockerCommand(ctx, "run", optionsString, workingDirectory, stdoutDataReceived, stderrDataReceived)
+	return ExecuteDockerCommand(ctx, "run", optionsString, workingDirectory, stdout, stderr)
 }
 
 func formatMountArg(volume Volume) string {
@@ -45,6 +45,8 @@ type SecretsProxy struct {
 
 type Evaluator struct {
 	ctx *ExecutionState
+	// failed is true if a previous step failed, see `success()` and `failure()`
+	failed bool
 }
 
 func NewEvaluator(ctx *ExecutionState) *Evaluator {
@@ -192,9 +194,9 @@ func (e *Evaluator) callFunction(name string, args []any) (any, error) {
 	case "always":
 		return true, nil
 	case "success":
-		return true, nil
+		return !e.failed, nil
 	case "failure":
-		return false, nil
+		return e.failed, nil
 	case "cancelled":
 		return false, nil
 
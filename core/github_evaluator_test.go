@@ -151,3 +151,39 @@ func TestEvaluate_GitHubParity(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateStepCondition(t *testing.T) {
+	ctx := ExecutionState{
+		DataOutputCache: map[string]any{
+			"build": map[string]any{
+				"outputs":    map[string]any{"version": "1.2.3"},
+				"outcome":    "failure",
+				"conclusion": "success",
+			},
+		},
+	}
+
+	tests := []struct {
+		condition string
+		failed    bool
+		expected  bool
+	}{
+		{condition: "", failed: false, expected: true},
+		{condition: "", failed: true, expected: false},
+		{condition: "steps.build.outputs.version == '1.2.3'", failed: false, expected: true},
+		{condition: "${{ steps.build.outputs.version == '1.2.3' }}", failed: true, expected: false},
+		{condition: "steps.build.outcome == 'failure'", failed: false, expected: true},
+		{condition: "always()", failed: true, expected: true},
+		{condition: "failure()", failed: false, expected: false},
+		{condition: "${{ failure() }}", failed: true, expected: true},
+		{condition: "success() || steps.build.conclusion == 'success'", failed: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			result, err := EvaluateStepCondition(&ctx, tt.condition, tt.failed)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -26,6 +28,25 @@ type RunOpts struct {
 	OverrideInputs  map[string]any
 	OverrideEnv     map[string]string
 	Args            []string
+
+	// Values of graph inputs as 'name=value', e.g. from `--input`.
+	Inputs []string
+	// JSON or YAML file with the values of graph inputs. See `LoadInputsFile`.
+	InputsFile string
+
+	// Directory where the run journal is written to. See `RunJournal`.
+	RunDir string
+	// Id of an interrupted run in `RunDir` that should be resumed.
+	ResumeRunId string
+	// File path or file descriptor number to write run events to. See `EventStream`.
+	Events string
+	// Base URL of an OpenTelemetry collector to export node spans to. See `Tracer`.
+	OtlpEndpoint string
+	// Directory to cache the results of nodes with `cache: true` in. See `MemoEntry`.
+	CacheDir string
+	// Whether the environment of a GitHub Actions runner is emulated. See `EmulateGitHubRunner`.
+	// Emulated runs handle the workflow commands locally, even though `GITHUB_ACTIONS` is set.
+	GhEmulate bool
 }
 
 type ActionGraph struct {
@@ -33,6 +54,9 @@ type ActionGraph struct {
 	Inputs  map[InputId]InputDefinition   `yaml:"inputs" json:"inputs" bson:"inputs"`
 	Outputs map[OutputId]OutputDefinition `yaml:"outputs" json:"outputs" bson:"outputs"`
 
+	// Capacities of the named semaphores of the graph. See `Semaphores`.
+	Semaphores map[string]int64
+
 	Entry string
 }
 
@@ -135,6 +159,19 @@ func (m valueMap[T]) set(source map[string]T, sourceName string, explicit bool,
 	}
 }
 
+// setFold is like `set`, but also replaces values whose keys only differ in case.
+func (m valueMap[T]) setFold(source map[string]T, sourceName string, explicit bool, hideValue bool) {
+	for _, k := range slices.Sorted(maps.Keys(source)) {
+		for existing, v := range m.data {
+			if existing != k && strings.EqualFold(existing, k) {
+				delete(m.data, existing)
+				m.data[k] = v
+			}
+		}
+		m.setSingle(k, source[k], sourceName, explicit, hideValue)
+	}
+}
+
 func (m valueMap[T]) setSingle(key string, value T, sourceName string, explicit bool, hideValue bool) {
 	newVal := formatValue(key, value, hideValue)
 
@@ -261,18 +298,21 @@ func NewExecutionState(
 		GhMatrix:  ghMatrix,
 		GhNeeds:   ghNeeds,
 
+		PostSteps:   &PostSteps{},
+		Annotations: &Annotations{},
+
 		DataOutputCache:      make(map[string]any),
 		ExecutionOutputCache: make(map[string]any),
 	}
 }
 
-func RunGraph(ctx context.Context, graphName string, graphContent []byte, opts RunOpts, debugCb DebugCallback) error {
+func RunGraph(ctx context.Context, graphName string, graphContent []byte, opts RunOpts, debugCb DebugCallback) (err error) {
 	graphYaml := make(map[string]any)
 	if err := yaml.Unmarshal(graphContent, &graphYaml); err != nil {
 		return CreateErr(nil, err, "failed to load yaml")
 	}
 
-	ag, errs := LoadGraph(graphYaml, nil, "", false)
+	ag, errs := LoadGraph(graphYaml, nil, "", GraphLoadContext{Ctx: ctx, Dir: graphFileDir(graphName)}, false)
 	if len(errs) > 0 {
 		return CreateErr(nil, errs[0], "failed to load graph")
 	}
@@ -377,11 +417,36 @@ func RunGraph(ctx context.Context, graphName string, graphContent []byte, opts R
 		}
 	}
 
-	// prio 4 (highest): explicit overrides (eg from the web app)
+	// prio 4: inputs from the command line. A file of inputs has a lower precedence than
+	// arguments of the graph that match its inputs, which have a lower precedence than `--input`.
+	if opts.InputsFile != "" {
+		m, err := LoadInputsFile(opts.InputsFile)
+		if err != nil {
+			return err
+		}
+		inputTracker.setFold(m, fmt.Sprintf("inputs file (%s)", filepath.Base(opts.InputsFile)), true, false)
+	}
+
+	for k, v := range graphInputArgs(ag.Inputs, opts.Args) {
+		inputTracker.setFold(map[string]any{k: v}, fmt.Sprintf("argument (--%s)", k), true, false)
+	}
+
+	cliInputs, err := parseInputFlags(ag.Inputs, opts.Inputs)
+	if err != nil {
+		return err
+	}
+	inputTracker.setFold(cliInputs, "--input", true, false)
+
+	// prio 5 (highest): explicit overrides (eg from the web app)
 	envTracker.set(opts.OverrideEnv, "override", true, false)
 	inputTracker.set(opts.OverrideInputs, "override", true, false)
 	secretTracker.set(opts.OverrideSecrets, "override", true, true)
 
+	err = resolveGraphInputs(ag.Inputs, inputTracker)
+	if err != nil {
+		return err
+	}
+
 	finalEnv := envTracker.toSimpleMap()
 	finalInputs := inputTracker.toSimpleMapWithLowercaseKeys()
 	finalSecrets := secretTracker.toSimpleMap()
@@ -419,14 +484,92 @@ func RunGraph(ctx context.Context, graphName string, graphContent []byte, opts R
 		needsTracker.toSimpleMap(),
 	)
 
+	// a graph with a gh-start entry can also run locally, then no runner parses the output
+	c.IsGitHubRunner = os.Getenv("GITHUB_ACTIONS") == "true" && !opts.GhEmulate
+	c.Semaphores = NewSemaphores(ag.Semaphores)
+	c.MemoDir = opts.CacheDir
+
+	if opts.RunDir != "" {
+		c.Journal, err = OpenRunJournal(opts.RunDir, opts.ResumeRunId, graphName, graphContent, c)
+		if err != nil {
+			return err
+		}
+		// finished last, also if the run fails before the entry is executed
+		defer func() {
+			journalErr := c.Journal.Finish(c, err)
+			if err == nil {
+				err = journalErr
+			}
+		}()
+		utils.LogOut.Infof("📓 Run journal: %s\n", c.Journal.GetPath())
+	} else if opts.ResumeRunId != "" {
+		return CreateErr(nil, nil, "resuming run '%s' requires a run directory", opts.ResumeRunId).
+			SetHint("Pass the '--run_dir' that was used for the interrupted run.")
+	}
+
+	if opts.Events != "" {
+		c.Events, err = OpenEventStream(opts.Events)
+		if err != nil {
+			return err
+		}
+		c.Events.runStarted(c)
+		defer func() {
+			c.Events.nodeFinished(c, c.span, "", err)
+			c.Events.runFinished(c, err)
+			eventsErr := c.Events.Close()
+			if err == nil {
+				err = eventsErr
+			}
+		}()
+	}
+
+	if opts.OtlpEndpoint != "" {
+		c.Tracer, err = NewTracer(ctx, opts.OtlpEndpoint)
+		if err != nil {
+			return err
+		}
+		c.Tracer.runStarted(c)
+	}
+
 	if isBaseNode {
 		c.PushNodeVisit(entryNode, true)
+		c.activeNode = entryNode
+		if c.Events != nil {
+			c.span = c.Events.nodeEntered(c, entryNode, "")
+		}
+		if c.Tracer != nil {
+			c.Tracer.nodeEntered(c, entryNode, "")
+		}
 	}
 
-	return entry.ExecuteEntry(c, nil, opts.Args)
+	err = entry.ExecuteEntry(c, nil, opts.Args)
+	if err == nil && ctx.Err() != nil {
+		// nodes stop executing once the run is cancelled without returning an error
+		err = CreateErr(nil, ctx.Err(), "run was cancelled")
+	}
+
+	postErr := c.PostSteps.run(err)
+	if err == nil {
+		err = postErr
+	}
+	c.Annotations.printSummary(c.IsGitHubRunner)
+	c.CloseStreams()
+
+	if c.Tracer != nil {
+		if isBaseNode {
+			c.Tracer.nodeFinished(c, entryNode, "", err)
+		}
+		c.Tracer.runFinished(c, err)
+		traceErr := c.Tracer.Shutdown(context.Background())
+		if err == nil {
+			err = traceErr
+		}
+	}
+
+	return err
 }
 
-func LoadGraph(graphYaml map[string]any, parent NodeBaseInterface, parentId string, validate bool) (ActionGraph, []error) {
+func LoadGraph(graphYaml map[string]any, parent NodeBaseInterface, parentId string, lc GraphLoadContext, validate bool) (ActionGraph, []error) {
 
 	var (
 		collectedErrors []error
@@ -435,6 +578,14 @@ func LoadGraph(graphYaml map[string]any, parent NodeBaseInterface, parentId stri
 
 	ag := NewActionGraph()
 
+	err = migrateLoadedGraph(graphYaml, parentId)
+	if err != nil {
+		if !validate {
+			return ActionGraph{}, []error{err}
+		}
+		collectedErrors = append(collectedErrors, err)
+	}
+
 	ag.Inputs, err = LoadGraphInputs(graphYaml)
 	if err != nil {
 		if !validate {
@@ -451,11 +602,27 @@ func LoadGraph(graphYaml map[string]any, parent NodeBaseInterface, parentId stri
 		collectedErrors = append(collectedErrors, err)
 	}
 
-	err = LoadNodes(&ag, parent, parentId, graphYaml, validate, &collectedErrors)
+	ag.Semaphores, err = LoadGraphSemaphores(graphYaml)
+	if err != nil {
+		if !validate {
+			return ActionGraph{}, []error{err}
+		}
+		collectedErrors = append(collectedErrors, err)
+	}
+
+	err = LoadNodes(&ag, parent, parentId, graphYaml, lc, validate, &collectedErrors)
 	if err != nil && !validate {
 		return ActionGraph{}, []error{err}
 	}
 
+	err = mergeGroupSemaphores(&ag)
+	if err != nil {
+		if !validate {
+			return ActionGraph{}, []error{err}
+		}
+		collectedErrors = append(collectedErrors, err)
+	}
+
 	err = LoadExecutions(&ag, graphYaml, validate, &collectedErrors)
 	if err != nil && !validate {
 		return ActionGraph{}, []error{err}
@@ -474,6 +641,33 @@ func LoadGraph(graphYaml map[string]any, parent NodeBaseInterface, parentId stri
 	return ag, collectedErrors
 }
 
+// mergeGroupSemaphores adds the semaphores defined by the graphs of groups, like a
+// referenced graph, to the semaphores of the graph. A run has one set of semaphores,
+// so a semaphore that is defined more than once must have the same capacity.
+func mergeGroupSemaphores(ag *ActionGraph) error {
+	for _, nodeId := range slices.Sorted(maps.Keys(ag.Nodes)) {
+		node := ag.Nodes[nodeId]
+		groupGraph := node.GetGraph()
+		if !isGroupNode(node) || groupGraph == nil {
+			continue
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(groupGraph.Semaphores)) {
+			capacity := groupGraph.Semaphores[name]
+			existing, ok := ag.Semaphores[name]
+			if ok && existing != capacity {
+				return CreateErr(nil, nil, "semaphore '%s' is defined with a capacity of %d by group '%s' and with %d by its parent graph", name, capacity, node.GetFullPath(), existing).
+					SetHint("use the same capacity for the semaphore in all graphs, they share it during a run")
+			}
+			if ag.Semaphores == nil {
+				ag.Semaphores = map[string]int64{}
+			}
+			ag.Semaphores[name] = capacity
+		}
+	}
+	return nil
+}
+
 func LoadGraphInputs(graphYaml map[string]any) (map[InputId]InputDefinition, error) {
 	inputs, ok := graphYaml["inputs"]
 	if !ok {
@@ -529,14 +723,14 @@ func anyToPortDefinition[T any](o any) (T, error) {
 	return ret, err
 }
 
-func LoadNodes(ag *ActionGraph, parent NodeBaseInterface, parentId string, nodesYaml map[string]any, validate bool, errs *[]error) error {
+func LoadNodes(ag *ActionGraph, parent NodeBaseInterface, parentId string, nodesYaml map[string]any, lc GraphLoadContext, validate bool, errs *[]error) error {
 	nodesList, err := utils.GetTypedPropertyByPath[[]any](nodesYaml, "nodes")
 	if err != nil {
 		return collectOrReturn(err, validate, errs)
 	}
 
 	for _, nodeData := range nodesList {
-		n, id, err := LoadNode(parent, parentId, nodeData, validate, errs)
+		n, id, err := LoadNode(parent, parentId, nodeData, lc, validate, errs)
 		if err != nil {
 			return err
 		}
@@ -551,7 +745,7 @@ func LoadNodes(ag *ActionGraph, parent NodeBaseInterface, parentId string, nodes
 	return nil
 }
 
-func LoadNode(parent NodeBaseInterface, parentId string, nodeData any, validate bool, errs *[]error) (NodeBaseInterface, string, error) {
+func LoadNode(parent NodeBaseInterface, parentId string, nodeData any, lc GraphLoadContext, validate bool, errs *[]error) (NodeBaseInterface, string, error) {
 	nodeI, ok := nodeData.(map[string]any)
 	if !ok {
 		err := CreateErr(nil, nil, "node is not a map")
@@ -596,7 +790,7 @@ func LoadNode(parent NodeBaseInterface, parentId string, nodeData any, validate
 	if strings.HasPrefix(nodeType, "github.com/") {
 		n, factoryErrs = NewGhActionNode(nodeType, parent, fullPath, validate)
 	} else {
-		n, factoryErrs = NewNodeInstance(nodeType, parent, fullPath, nodeI, validate)
+		n, factoryErrs = newNodeInstance(&lc, nodeType, parent, fullPath, nodeI, validate)
 	}
 
 	if len(factoryErrs) > 0 {
@@ -623,6 +817,26 @@ func LoadNode(parent NodeBaseInterface, parentId string, nodeData any, validate
 		}
 	}
 
+	policy, policyErr := LoadExecutionPolicy(n, nodeI)
+	if policyErr != nil {
+		policyErr = CreateErr(nil, policyErr, "invalid execution policy for node '%s'", id)
+		if collectOrReturn(policyErr, validate, errs) != nil {
+			return nil, "", policyErr
+		}
+	} else {
+		n.SetExecutionPolicy(policy)
+	}
+
+	memoize, memoizeErr := LoadMemoize(n, nodeI)
+	if memoizeErr != nil {
+		memoizeErr = CreateErr(nil, memoizeErr, "invalid cache setting for node '%s'", id)
+		if collectOrReturn(memoizeErr, validate, errs) != nil {
+			return nil, "", memoizeErr
+		}
+	} else {
+		n.SetMemoized(memoize)
+	}
+
 	// We continue to check inputs/outputs even if factoryErrs occurred,
 	// provided 'n' exists.
 	inputErr := LoadInputValues(n, nodeI, validate, errs)
@@ -869,7 +1083,7 @@ func LoadExecutions(ag *ActionGraph, nodesYaml map[string]any, validate bool, er
 
 			_, _, ok = srcOutputNode.OutputDefByPortId(srcPort)
 			if !ok {
-				err := CreateErr(nil, nil, "src node '%s' (%s) has no execution output '%s'", srcNode.GetName(), srcNodeId, srcPort)
+				err := graphRefPortError(CreateErr(nil, nil, "src node '%s' (%s) has no execution output '%s'", srcNode.GetName(), srcNodeId, srcPort), srcNode)
 				if collectOrReturn(err, validate, errs) != nil {
 					return err
 				}
@@ -890,7 +1104,7 @@ func LoadExecutions(ag *ActionGraph, nodesYaml map[string]any, validate bool, er
 
 			_, _, ok = dstInputNode.InputDefByPortId(dstPort)
 			if !ok {
-				err := CreateErr(nil, nil, "dst node '%s' (%s) has no execution input '%s'", dstNode.GetName(), dstNodeId, dstPort)
+				err := graphRefPortError(CreateErr(nil, nil, "dst node '%s' (%s) has no execution input '%s'", dstNode.GetName(), dstNodeId, dstPort), dstNode)
 				if collectOrReturn(err, validate, errs) != nil {
 					return err
 				}
@@ -988,10 +1202,11 @@ func LoadConnections(ag *ActionGraph, nodesYaml map[string]any, parent NodeBaseI
 		// This calls PortsAreCompatible internally (via ConnectDataPort in inputs.go)
 		// If that fails, it returns an error which we collect here.
 		err = dstInputNode.ConnectDataPort(srcNode, srcPort, dstNode, dstPort, parent, ConnectOpts{
-			SkipValidation: strings.HasPrefix(srcNode.GetNodeTypeId(), "core/group@") || strings.HasPrefix(dstNode.GetNodeTypeId(), "core/group@"),
+			SkipValidation: isInlineGroupNode(srcNode) || isInlineGroupNode(dstNode),
 		})
 		if err != nil {
-			if collectOrReturn(CreateErr(nil, err, "failed to connect data ports"), validate, errs) != nil {
+			err = graphRefPortError(CreateErr(nil, err, "failed to connect data ports"), srcNode, dstNode)
+			if collectOrReturn(err, validate, errs) != nil {
 				return err
 			}
 			continue
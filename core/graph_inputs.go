@@ -0,0 +1,246 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// LoadInputsFile loads the values of graph inputs from a JSON or YAML file with a map of input names to values.
+func LoadInputsFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, CreateErr(nil, err, "failed to read inputs file '%s'", path)
+	}
+
+	// yaml is a superset of json, so both are parsed the same way
+	var inputs map[string]any
+	err = yaml.Unmarshal(content, &inputs)
+	if err != nil {
+		return nil, CreateErr(nil, err, "failed to parse inputs file '%s'", path).
+			SetHint("the file must contain a JSON or YAML map of input names to values")
+	}
+	return inputs, nil
+}
+
+// parseInputFlags parses the values of `--input name=value` flags.
+func parseInputFlags(defs map[InputId]InputDefinition, flags []string) (map[string]any, error) {
+	inputs := map[string]any{}
+	for _, f := range flags {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || name == "" {
+			return nil, CreateErr(nil, nil, "invalid input '%s'", f).
+				SetHint("inputs are passed as '--input name=value'")
+		}
+
+		if len(defs) > 0 {
+			id, ok := findGraphInput(defs, name)
+			if !ok {
+				return nil, CreateErr(nil, nil, "graph has no input '%s'", name).
+					SetHint("the inputs of the graph are: %s", strings.Join(graphInputNames(defs), ", "))
+			}
+			name = string(id)
+		}
+		inputs[name] = value
+	}
+	return inputs, nil
+}
+
+// graphInputArgs returns the values of the graph arguments `--name=value` whose name
+// is a declared input of the graph. A bool input can also be set with `--name`.
+// Other arguments are left to the graph.
+func graphInputArgs(defs map[InputId]InputDefinition, args []string) map[string]any {
+	inputs := map[string]any{}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !strings.HasPrefix(arg, "--") || name == "" {
+			continue
+		}
+
+		id, ok := findGraphInput(defs, name)
+		if !ok {
+			continue
+		}
+
+		if hasValue {
+			inputs[string(id)] = value
+		} else if defs[id].Type == "bool" && !defs[id].Array {
+			inputs[string(id)] = true
+		}
+	}
+	return inputs
+}
+
+// resolveGraphInputs converts the values of the declared graph inputs to their type, and
+// checks that their options and required flags are satisfied. Inputs without a value get
+// their default value.
+func resolveGraphInputs(defs map[InputId]InputDefinition, inputs valueMap[any]) error {
+	for _, id := range graphInputIds(defs) {
+		def := defs[id]
+
+		// the values are looked up case-insensitively, like `${{ inputs.name }}`
+		var tv *trackedValue[any]
+		for key, v := range inputs.data {
+			if strings.EqualFold(key, string(id)) {
+				tv = &v
+				delete(inputs.data, key)
+				break
+			}
+		}
+
+		if tv == nil || tv.Value == nil {
+			if def.Default != nil {
+				inputs.setSingle(string(id), def.Default, "default", false, false)
+				continue
+			} else if def.Required {
+				return CreateErr(nil, nil, "graph input '%s' is required", id).
+					SetHint("pass it with '--input %s=<value>', as argument '--%s=<value>' or in an inputs file", id, id)
+			}
+			continue
+		}
+
+		value, err := convertGraphInput(def, tv.Value)
+		if err != nil {
+			return CreateErr(nil, err, "invalid value for graph input '%s' (from %s)", id, tv.Source)
+		}
+		inputs.data[string(id)] = trackedValue[any]{
+			Key:        string(id),
+			Value:      value,
+			Source:     tv.Source,
+			Category:   tv.Category,
+			IsExplicit: tv.IsExplicit,
+		}
+	}
+	return nil
+}
+
+// convertGraphInput converts a value to the type of a graph input. Values from the
+// command line and environment variables are strings, arrays are given as JSON arrays
+// or comma-separated lists.
+func convertGraphInput(def InputDefinition, value any) (any, error) {
+	if !def.Array {
+		return convertGraphInputElem(def, value)
+	}
+
+	var elems []any
+	switch v := value.(type) {
+	case []any:
+		elems = v
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "[") {
+			err := json.Unmarshal([]byte(v), &elems)
+			if err != nil {
+				return nil, CreateErr(nil, err, "'%s' is not a valid JSON array", v)
+			}
+		} else if v != "" {
+			for _, elem := range strings.Split(v, ",") {
+				elems = append(elems, strings.TrimSpace(elem))
+			}
+		}
+	default:
+		elems = []any{v}
+	}
+
+	result := make([]any, 0, len(elems))
+	for _, elem := range elems {
+		converted, err := convertGraphInputElem(def, elem)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+func convertGraphInputElem(def InputDefinition, value any) (any, error) {
+	switch def.Type {
+	case "string", "secret":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool, int, int64, float64:
+			return fmt.Sprint(v), nil
+		}
+	case "number":
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return v, nil
+		case float64:
+			return v, nil
+		case string:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return int(i), nil
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	case "option":
+		s := fmt.Sprint(value)
+		if len(def.Options) == 0 {
+			return s, nil
+		}
+		var values []string
+		for _, o := range def.Options {
+			if o.Value == s {
+				return s, nil
+			}
+			values = append(values, o.Value)
+		}
+		return nil, CreateErr(nil, nil, "'%s' is not one of the options of the input", s).
+			SetHint("use one of: %s", strings.Join(values, ", "))
+	default:
+		return value, nil
+	}
+
+	return nil, CreateErr(nil, nil, "'%v' is not a valid %s", value, def.Type)
+}
+
+// graphInputIds returns the ids of the data inputs of a graph.
+func graphInputIds(defs map[InputId]InputDefinition) []InputId {
+	var ids []InputId
+	for _, id := range slices.Sorted(maps.Keys(defs)) {
+		if !defs[id].Exec {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func graphInputNames(defs map[InputId]InputDefinition) []string {
+	var names []string
+	for _, id := range graphInputIds(defs) {
+		names = append(names, string(id))
+	}
+	return names
+}
+
+func findGraphInput(defs map[InputId]InputDefinition, name string) (InputId, bool) {
+	for _, id := range graphInputIds(defs) {
+		if strings.EqualFold(string(id), name) {
+			return id, true
+		}
+	}
+	return "", false
+}
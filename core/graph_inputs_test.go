@@ -0,0 +1,81 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertGraphInput(t *testing.T) {
+	number := InputDefinition{PortDefinition: PortDefinition{Type: "number"}}
+	boolean := InputDefinition{PortDefinition: PortDefinition{Type: "bool"}}
+	option := InputDefinition{PortDefinition: PortDefinition{Type: "option"}}
+	option.Options = []InputOption{{Name: "Staging", Value: "staging"}, {Name: "Production", Value: "production"}}
+	tags := InputDefinition{PortDefinition: PortDefinition{Type: "string", Array: true}}
+
+	for _, tc := range []struct {
+		def   InputDefinition
+		value any
+		want  any
+	}{
+		{number, "3", 3},
+		{number, "1.5", 1.5},
+		{number, 7, 7},
+		{boolean, "true", true},
+		{boolean, false, false},
+		{option, "production", "production"},
+		{tags, "a, b", []any{"a", "b"}},
+		{tags, `["a", "b"]`, []any{"a", "b"}},
+		{tags, []any{"a", 1}, []any{"a", "1"}},
+		{tags, "", []any{}},
+	} {
+		got, err := convertGraphInput(tc.def, tc.value)
+		assert.NoError(t, err, tc.value)
+		assert.Equal(t, tc.want, got, tc.value)
+	}
+
+	for _, tc := range []struct {
+		def   InputDefinition
+		value any
+	}{
+		{number, "many"},
+		{boolean, "maybe"},
+		{option, "prod"},
+		{tags, "[a"},
+	} {
+		_, err := convertGraphInput(tc.def, tc.value)
+		assert.Error(t, err, tc.value)
+	}
+}
+
+func TestGraphInputArgs(t *testing.T) {
+	defs := map[InputId]InputDefinition{
+		"target":  {PortDefinition: PortDefinition{Type: "string"}},
+		"verbose": {PortDefinition: PortDefinition{Type: "bool"}},
+		"exec":    {PortDefinition: PortDefinition{Exec: true}},
+	}
+
+	inputs := graphInputArgs(defs, []string{"--Target=prod", "--verbose", "--other=1", "--exec=1", "positional", "--", "--target=ignored"})
+	assert.Equal(t, map[string]any{"target": "prod", "verbose": true}, inputs)
+}
+
+func TestParseInputFlags(t *testing.T) {
+	defs := map[InputId]InputDefinition{
+		"target": {PortDefinition: PortDefinition{Type: "string"}},
+	}
+
+	inputs, err := parseInputFlags(defs, []string{"TARGET=a=b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"target": "a=b"}, inputs)
+
+	_, err = parseInputFlags(defs, []string{"other=1"})
+	assert.Error(t, err)
+
+	_, err = parseInputFlags(defs, []string{"target"})
+	assert.Error(t, err)
+
+	// graphs without declared inputs accept any name
+	inputs, err = parseInputFlags(nil, []string{"other=1"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"other": "1"}, inputs)
+}
@@ -0,0 +1,498 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	gh_workflow_yml "github.com/actionforge/actrun-cli/github/workflow.yml"
+	"github.com/actionforge/actrun-cli/utils"
+	"go.yaml.in/yaml/v4"
+)
+
+// importedGraph is the graph file that `ImportWorkflow` writes.
+type importedGraph struct {
+	Entry       string                     `yaml:"entry"`
+	Type        string                     `yaml:"type"`
+	Inputs      map[string]InputDefinition `yaml:"inputs,omitempty"`
+	Nodes       []importedNode             `yaml:"nodes"`
+	Connections []importedLink             `yaml:"connections"`
+	Executions  []importedLink             `yaml:"executions"`
+}
+
+type importedNode struct {
+	Id       string           `yaml:"id"`
+	Type     string           `yaml:"type"`
+	Position importedPosition `yaml:"position"`
+	Inputs   map[string]any   `yaml:"inputs,omitempty"`
+	Timeout  string           `yaml:"timeout,omitempty"`
+}
+
+type importedPosition struct {
+	X int
+	Y int
+}
+
+// MarshalYAML writes the position like the editor does. The key 'y' would be quoted
+// otherwise, since it's a bool in YAML 1.1.
+func (p importedPosition) MarshalYAML() (any, error) {
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "x"},
+			{Kind: yaml.ScalarNode, Value: strconv.Itoa(p.X)},
+			{Kind: yaml.ScalarNode, Value: "y"},
+			{Kind: yaml.ScalarNode, Value: strconv.Itoa(p.Y)},
+		},
+	}, nil
+}
+
+type importedPort struct {
+	Node string `yaml:"node"`
+	Port string `yaml:"port"`
+}
+
+type importedLink struct {
+	Src importedPort `yaml:"src"`
+	Dst importedPort `yaml:"dst"`
+}
+
+var (
+	// status functions other than `success()` have no equivalent in a linear graph
+	reFailureStatusFunction = regexp.MustCompile(`(?i)\b(failure|always|cancelled)\s*\(`)
+	reStepsContext          = regexp.MustCompile(`\bsteps\.`)
+	reStepOutput            = regexp.MustCompile(`^\$\{\{\s*steps\.([\w-]+)\.outputs\.([\w-]+)\s*\}\}$`)
+	reNonIdChars            = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// workflowImporter converts the steps of a job into nodes that run one after another.
+type workflowImporter struct {
+	workflow *gh_workflow_yml.GhWorkflow
+	job      *gh_workflow_yml.Job
+	graph    importedGraph
+	warnings []string
+
+	nodeIds map[string]bool
+	// ids of the steps with `uses` mapped to the ids of their nodes
+	actionNodes map[string]string
+	// the execution outputs that continue with the next node
+	sources []importedPort
+	// the number of executed nodes, which are placed in a row
+	columns int
+}
+
+// ImportWorkflowFile converts a job of a GitHub Actions workflow file into a graph. See `ImportWorkflow`.
+func ImportWorkflowFile(workflowFile string, jobId string) ([]byte, []string, error) {
+	content, err := os.ReadFile(workflowFile)
+	if err != nil {
+		return nil, nil, CreateErr(nil, err, "failed to read workflow file '%s'", workflowFile)
+	}
+	return ImportWorkflow(content, jobId)
+}
+
+// ImportWorkflow converts the steps of a job of a GitHub Actions workflow into a graph that
+// runs them one after another. The graph starts with `core/gh-start@v1`, connected for the
+// triggers of the workflow. Steps with `uses` become action nodes, steps with `run` become
+// `core/run@v1` nodes and an `if` becomes a `core/branch@v1` node in front of its step.
+// `jobId` can be empty if the workflow has a single job. Constructs without an equivalent
+// in graphs, like a matrix, are skipped and returned as warnings.
+func ImportWorkflow(content []byte, jobId string) ([]byte, []string, error) {
+	var workflow gh_workflow_yml.GhWorkflow
+	err := yaml.Unmarshal(content, &workflow)
+	if err != nil {
+		return nil, nil, CreateErr(nil, err, "failed to parse workflow")
+	}
+
+	jobIds := slices.Sorted(maps.Keys(workflow.Jobs))
+	if jobId == "" {
+		if len(jobIds) != 1 {
+			return nil, nil, CreateErr(nil, nil, "workflow has %d jobs, select the job to import", len(jobIds)).
+				SetHint("the jobs of the workflow are: %s", strings.Join(jobIds, ", "))
+		}
+		jobId = jobIds[0]
+	}
+
+	job, ok := workflow.Jobs[jobId]
+	if !ok {
+		return nil, nil, CreateErr(nil, nil, "workflow has no job '%s'", jobId).
+			SetHint("the jobs of the workflow are: %s", strings.Join(jobIds, ", "))
+	}
+	if job.Uses != "" {
+		return nil, nil, CreateErr(nil, nil, "job '%s' calls the reusable workflow '%s'", jobId, job.Uses).
+			SetHint("only jobs with steps can be imported, import the job of the reusable workflow instead")
+	}
+
+	imp := &workflowImporter{
+		workflow:    &workflow,
+		job:         &job,
+		nodeIds:     map[string]bool{},
+		actionNodes: map[string]string{},
+		graph: importedGraph{
+			Entry:       "gh-start",
+			Type:        "generic",
+			Connections: []importedLink{},
+			Executions:  []importedLink{},
+		},
+	}
+
+	imp.importJobSettings(jobId)
+	imp.importTriggers()
+	imp.importInputs()
+	for i, step := range job.Steps {
+		imp.importStep(i, step)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(imp.graph)
+	if err != nil {
+		return nil, nil, CreateErr(nil, err, "failed to write yaml")
+	}
+	return buf.Bytes(), imp.warnings, nil
+}
+
+func (imp *workflowImporter) warn(format string, args ...any) {
+	imp.warnings = append(imp.warnings, fmt.Sprintf(format, args...))
+}
+
+// importJobSettings reports the settings of the job that the graph doesn't take over.
+func (imp *workflowImporter) importJobSettings(jobId string) {
+	job := imp.job
+	if len(job.Needs) > 0 {
+		imp.warn("job '%s' needs other jobs (%s), only the steps of the job are imported", jobId, strings.Join(job.Needs, ", "))
+	}
+	if job.If != "" {
+		imp.warn("the condition of job '%s' is not imported: %s", jobId, job.If)
+	}
+	if job.Strategy != nil {
+		imp.warn("the strategy of job '%s' is not imported, 'matrix' values are empty in the graph", jobId)
+	}
+	if job.Container.Image != "" {
+		imp.warn("the container of job '%s' is not imported, the steps run on the host", jobId)
+	}
+	if len(job.Services) > 0 {
+		imp.warn("the services of job '%s' are not imported", jobId)
+	}
+	if len(job.Outputs) > 0 {
+		imp.warn("the outputs of job '%s' are not imported", jobId)
+	}
+	if job.TimeoutMinutes > 0 {
+		imp.warn("the 'timeout-minutes' of job '%s' is not imported", jobId)
+	}
+	if job.ContinueOnError.Value || job.ContinueOnError.Expression != "" {
+		imp.warn("the 'continue-on-error' of job '%s' is not imported", jobId)
+	}
+}
+
+// importTriggers adds the start node and connects the ports of the events the workflow is triggered by.
+func (imp *workflowImporter) importTriggers() {
+	imp.addNode(importedNode{Id: "gh-start", Type: "core/gh-start@v1"})
+
+	startDef := registries["core/gh-start@v1"]
+	for _, event := range slices.Sorted(maps.Keys(imp.workflow.On.Events)) {
+		port := "exec-on_" + event
+		_, ok := startDef.Outputs[OutputId(port)]
+		if !ok {
+			imp.warn("event '%s' is not supported by 'core/gh-start@v1'", event)
+			continue
+		}
+		imp.sources = append(imp.sources, importedPort{Node: "gh-start", Port: port})
+
+		// the start node continues for every event of a type, whatever branch or path it concerns
+		eventConfig, _ := imp.workflow.On.Events[event].(map[string]any)
+		var filters []string
+		for _, key := range slices.Sorted(maps.Keys(eventConfig)) {
+			if key != "inputs" && key != "outputs" && key != "secrets" {
+				filters = append(filters, key)
+			}
+		}
+		if len(filters) > 0 {
+			imp.warn("the filters of event '%s' are not imported (%s)", event, strings.Join(filters, ", "))
+		}
+	}
+	if len(imp.sources) == 0 {
+		imp.warn("workflow has no supported event, connect the start node manually")
+	}
+}
+
+// importInputs turns the inputs of `workflow_dispatch` or `workflow_call` into graph inputs.
+func (imp *workflowImporter) importInputs() {
+	for _, event := range []string{"workflow_dispatch", "workflow_call"} {
+		eventConfig, _ := imp.workflow.On.Events[event].(map[string]any)
+		inputs, _ := eventConfig["inputs"].(map[string]any)
+		if len(inputs) == 0 {
+			continue
+		}
+
+		imp.graph.Inputs = map[string]InputDefinition{}
+		for i, name := range slices.Sorted(maps.Keys(inputs)) {
+			input, _ := inputs[name].(map[string]any)
+			def := InputDefinition{
+				PortDefinition: PortDefinition{
+					Name:  name,
+					Type:  "string",
+					Index: i,
+				},
+				Default: input["default"],
+			}
+			def.Desc, _ = input["description"].(string)
+			def.Required, _ = input["required"].(bool)
+
+			switch input["type"] {
+			case "boolean":
+				def.Type = "bool"
+			case "number":
+				def.Type = "number"
+			case "choice":
+				def.Type = "option"
+				options, _ := input["options"].([]any)
+				for _, option := range options {
+					value := fmt.Sprint(option)
+					def.Options = append(def.Options, InputOption{Name: value, Value: value})
+				}
+			}
+			imp.graph.Inputs[name] = def
+		}
+		return
+	}
+}
+
+func (imp *workflowImporter) importStep(index int, step gh_workflow_yml.Step) {
+	stepName := step.ID
+	if stepName == "" {
+		stepName = step.Name
+	}
+	if stepName == "" {
+		stepName = step.Uses
+	}
+	if stepName == "" {
+		stepName = fmt.Sprintf("#%d", index+1)
+	}
+
+	var node importedNode
+	switch {
+	case step.Uses != "":
+		if strings.HasPrefix(step.Uses, "./") || strings.HasPrefix(step.Uses, "docker://") || !strings.Contains(step.Uses, "@") {
+			imp.warn("step '%s' is skipped, the action '%s' is not supported, only actions of repositories like 'owner/repo@ref' are", stepName, step.Uses)
+			return
+		}
+		node = imp.importActionStep(stepName, step)
+	case step.Run != "":
+		node = imp.importRunStep(stepName, step)
+	default:
+		imp.warn("step '%s' is skipped, it has neither 'run' nor 'uses'", stepName)
+		return
+	}
+
+	if step.TimeoutMinutes > 0 {
+		node.Timeout = fmt.Sprintf("%dm", step.TimeoutMinutes)
+	}
+
+	sources := imp.sources
+	var skipped []importedPort
+	if step.If != "" {
+		if reFailureStatusFunction.MatchString(step.If) {
+			imp.warn("step '%s' uses a status function in its condition, which is evaluated as if the previous steps succeeded", stepName)
+		}
+		if reStepsContext.MatchString(step.If) {
+			imp.warn("the condition of step '%s' uses the 'steps' context, which is not available in graphs", stepName)
+		}
+		// the expression of the branch is evaluated like the `if` of a step
+		branchId := imp.newNodeId(node.Id + "-if")
+		imp.addNode(importedNode{
+			Id:     branchId,
+			Type:   "core/branch@v1",
+			Inputs: map[string]any{"expression": strings.TrimSpace(step.If)},
+		})
+		imp.connectExec(sources, branchId)
+		sources = []importedPort{{Node: branchId, Port: "exec-then"}}
+		skipped = []importedPort{{Node: branchId, Port: "exec-otherwise"}}
+	}
+
+	imp.addNode(node)
+	imp.connectExec(sources, node.Id)
+
+	imp.sources = append([]importedPort{{Node: node.Id, Port: "exec-success"}}, skipped...)
+	switch {
+	case step.ContinueOnError.IsBool && step.ContinueOnError.Value:
+		imp.sources = append(imp.sources, importedPort{Node: node.Id, Port: "exec-err"})
+	case step.ContinueOnError.Expression != "":
+		imp.warn("step '%s' has an expression for 'continue-on-error', which is not imported", stepName)
+	}
+}
+
+func (imp *workflowImporter) importActionStep(stepName string, step gh_workflow_yml.Step) importedNode {
+	uses, ref, _ := strings.Cut(step.Uses, "@")
+	node := importedNode{
+		Id:     imp.newNodeId(utils.If(step.ID != "", step.ID, uses[strings.LastIndex(uses, "/")+1:])),
+		Type:   "github.com/" + uses + "@" + ref,
+		Inputs: map[string]any{},
+	}
+	if step.ID != "" {
+		imp.actionNodes[step.ID] = node.Id
+	}
+
+	for _, inputName := range slices.Sorted(maps.Keys(step.With)) {
+		value := fmt.Sprint(step.With[inputName])
+
+		// an input that is set to the output of a previous action is connected to it
+		m := reStepOutput.FindStringSubmatch(value)
+		if m != nil {
+			srcNode, ok := imp.actionNodes[m[1]]
+			if ok {
+				imp.graph.Connections = append(imp.graph.Connections, importedLink{
+					Src: importedPort{Node: srcNode, Port: m[2]},
+					Dst: importedPort{Node: node.Id, Port: inputName},
+				})
+				continue
+			}
+		}
+		if reStepsContext.MatchString(value) {
+			imp.warn("input '%s' of step '%s' uses the 'steps' context, which is not available in graphs", inputName, stepName)
+		}
+		node.Inputs[inputName] = value
+	}
+
+	imp.importEnv(&node, stepName, step)
+	return node
+}
+
+func (imp *workflowImporter) importRunStep(stepName string, step gh_workflow_yml.Step) importedNode {
+	node := importedNode{
+		Id:     imp.newNodeId(utils.If(step.ID != "", step.ID, utils.If(step.Name != "", step.Name, "run"))),
+		Type:   "core/run@v1",
+		Inputs: map[string]any{},
+	}
+
+	shell := step.Shell
+	if shell == "" {
+		shell = utils.If(imp.job.Defaults.Run.Shell != "", imp.job.Defaults.Run.Shell, imp.workflow.Defaults.Run.Shell)
+	}
+	switch shell {
+	case "":
+		shell = "bash"
+		if strings.Contains(strings.ToLower(imp.job.RunsOn.Target), "windows") || slices.Contains(imp.job.RunsOn.Labels, "windows") {
+			shell = "pwsh"
+		}
+	case "bash", "pwsh", "python", "cmd":
+	case "sh":
+		shell = "bash"
+	case "powershell":
+		shell = "pwsh"
+		imp.warn("step '%s' uses 'powershell', which is run with 'pwsh' in the graph", stepName)
+	default:
+		imp.warn("step '%s' uses the custom shell '%s', which is run with 'bash' in the graph", stepName, shell)
+		shell = "bash"
+	}
+
+	script := step.Run
+	workingDir := step.WorkingDirectory
+	if workingDir == "" {
+		workingDir = utils.If(imp.job.Defaults.Run.WorkingDirectory != "", imp.job.Defaults.Run.WorkingDirectory, imp.workflow.Defaults.Run.WorkingDirectory)
+	}
+	if workingDir != "" {
+		switch shell {
+		case "python":
+			imp.warn("the working directory of step '%s' is not imported", stepName)
+		case "cmd":
+			script = fmt.Sprintf("cd /d \"%s\"\n%s", workingDir, script)
+		default:
+			script = fmt.Sprintf("cd \"%s\"\n%s", workingDir, script)
+		}
+	}
+
+	if reStepsContext.MatchString(script) {
+		imp.warn("the script of step '%s' uses the 'steps' context, which is not available in graphs", stepName)
+	}
+
+	node.Inputs["shell"] = shell
+	node.Inputs["script"] = script
+	imp.importEnv(&node, stepName, step)
+	return node
+}
+
+// importEnv sets the env vars of the workflow, the job and the step as the env vars of the node.
+func (imp *workflowImporter) importEnv(node *importedNode, stepName string, step gh_workflow_yml.Step) {
+	env := map[string]string{}
+	maps.Copy(env, imp.workflow.Env)
+	maps.Copy(env, imp.job.Env)
+	maps.Copy(env, step.Env)
+	if len(env) == 0 {
+		return
+	}
+
+	var envList []string
+	hasExpressions := false
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		if reStepsContext.MatchString(env[name]) {
+			imp.warn("env var '%s' of step '%s' uses the 'steps' context, which is not available in graphs", name, stepName)
+		}
+		hasExpressions = hasExpressions || strings.Contains(env[name], "${{")
+		envList = append(envList, name+"="+env[name])
+	}
+	if !hasExpressions {
+		node.Inputs["env"] = envList
+		return
+	}
+
+	// the items of an array value aren't evaluated, but the array inputs of `core/env-array@v1` are
+	envNode := importedNode{
+		Id:     imp.newNodeId(node.Id + "-env"),
+		Type:   "core/env-array@v1",
+		Inputs: map[string]any{},
+	}
+	for i, item := range envList {
+		envNode.Inputs[fmt.Sprintf("env[%d]", i)] = item
+	}
+	imp.addDataNode(envNode)
+	imp.graph.Connections = append(imp.graph.Connections, importedLink{
+		Src: importedPort{Node: envNode.Id, Port: "env"},
+		Dst: importedPort{Node: node.Id, Port: "env"},
+	})
+}
+
+func (imp *workflowImporter) newNodeId(name string) string {
+	id := strings.Trim(reNonIdChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if id == "" {
+		id = "step"
+	}
+	uniqueId := id
+	for i := 2; imp.nodeIds[uniqueId]; i++ {
+		uniqueId = fmt.Sprintf("%s-%d", id, i)
+	}
+	imp.nodeIds[uniqueId] = true
+	return uniqueId
+}
+
+// addNode adds a node that is executed, right of the previous one.
+func (imp *workflowImporter) addNode(node importedNode) {
+	node.Position = importedPosition{X: imp.columns * 300}
+	imp.columns++
+	imp.appendNode(node)
+}
+
+// addDataNode adds a node below the next executed node, which uses its output.
+func (imp *workflowImporter) addDataNode(node importedNode) {
+	node.Position = importedPosition{X: imp.columns * 300, Y: 200}
+	imp.appendNode(node)
+}
+
+func (imp *workflowImporter) appendNode(node importedNode) {
+	imp.nodeIds[node.Id] = true
+	imp.graph.Nodes = append(imp.graph.Nodes, node)
+}
+
+func (imp *workflowImporter) connectExec(sources []importedPort, nodeId string) {
+	for _, src := range sources {
+		imp.graph.Executions = append(imp.graph.Executions, importedLink{
+			Src: src,
+			Dst: importedPort{Node: nodeId, Port: "exec"},
+		})
+	}
+}
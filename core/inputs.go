@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -94,9 +95,11 @@ type HasInputsInterface interface {
 	SetInputDefs(inputs map[InputId]InputDefinition, opts SetDefsOpts)
 	GetInputDefs() map[InputId]InputDefinition
 	GetInputIndexPorts() map[string]IndexPortInfo
+	GetDataSources() map[InputId]DataSource
 
 	InputValueById(c *ExecutionState, host NodeWithInputs, inputId InputId, group *InputId) (value any, err error)
 	SetInputValue(inputId InputId, value any) error
+	GetInputValues() map[InputId]any
 	AddSubInput(portId string, groupPortId string, portIndex int) error
 
 	ConnectDataPort(outputNode NodeBaseInterface, outputPortId string, inputNode NodeBaseInterface, inputPortId string, parent NodeBaseInterface, opts ConnectOpts) error
@@ -118,6 +121,11 @@ func (n *Inputs) GetDataSource(inputId InputId) (DataSource, bool) {
 	return ds, ok
 }
 
+// GetDataSources returns the incoming data connections by the input they are connected to.
+func (n *Inputs) GetDataSources() map[InputId]DataSource {
+	return n.incomingDataConnection
+}
+
 func (n *Inputs) InputDefsClone() map[InputId]InputDefinition {
 	return maps.Clone(n.inputDefs)
 }
@@ -247,6 +255,8 @@ func (n *Inputs) ConnectDataPort(outputNode NodeBaseInterface, outputPortId stri
 			return CreateErr(nil, nil, "source port is an execution port, but destination port is a data port (%v.%v -> %v.%v)", outputNode.GetId(), outputPortId, inputNode.GetId(), inputPortId).SetHint("either the source port or the destination port must be changed to match the other")
 		} else if inputDef.Exec {
 			return CreateErr(nil, nil, "destination port is an execution port, but source port is a data port (%v.%v -> %v.%v)", outputNode.GetId(), outputPortId, inputNode.GetId(), inputPortId).SetHint("either the source port or the destination port must be changed to match the other")
+		} else if inputDef.HideSocket {
+			return CreateErr(nil, nil, "input '%v' of node '%v' (%v) can't be connected (%v.%v -> %v.%v)", inputPortId, inputNode.GetName(), inputNode.GetId(), outputNode.GetId(), outputPortId, inputNode.GetId(), inputPortId).SetHint("remove the connection and set the value of the input in the node instead")
 		}
 
 		if outputDef.Array && indexOutputInfo == nil {
@@ -1028,6 +1038,45 @@ func EvaluateToStringExpression(ctx *ExecutionState, raw string) (string, error)
 	return strRes, nil
 }
 
+// EvaluateCondition evaluates a condition like the `if` of a GitHub Actions step.
+// The `${{ }}` around the expression are optional.
+func EvaluateCondition(ctx *ExecutionState, condition string) (bool, error) {
+	condition = strings.TrimSpace(condition)
+	if !strings.HasPrefix(condition, "${{") {
+		condition = "${{ " + condition + " }}"
+	}
+
+	res, err := NewEvaluator(ctx).Evaluate(condition)
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(res), nil
+}
+
+var reStatusFunction = regexp.MustCompile(`(?i)\b(success|failure|always|cancelled)\s*\(`)
+
+// EvaluateStepCondition evaluates the `if` of a step that follows other steps. Like in
+// GitHub Actions, a condition without a status function is only true if no previous
+// step failed, and an empty condition is the same as `success()`.
+func EvaluateStepCondition(ctx *ExecutionState, condition string, failed bool) (bool, error) {
+	expr := strings.TrimSpace(condition)
+	if strings.HasPrefix(expr, "${{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[3 : len(expr)-2])
+	}
+	if expr == "" {
+		expr = "success()"
+	} else if !reStatusFunction.MatchString(expr) {
+		expr = fmt.Sprintf("success() && (%s)", expr)
+	}
+
+	e := &Evaluator{ctx: ctx, failed: failed}
+	res, err := e.Evaluate("${{ " + expr + " }}")
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(res), nil
+}
+
 // ContextAdapter maps the evaluator's request for variables (e.g., "inputs.foo")
 // to the actual data inside your ExecutionState.
 type ContextAdapter struct {
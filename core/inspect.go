@@ -0,0 +1,235 @@
+package core
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// GraphInterface describes what a graph expects from the environment it runs in:
+// its inputs and outputs, and the secrets, environment variables and GitHub Actions
+// used by its nodes, including the nodes in its groups.
+type GraphInterface struct {
+	Entry         GraphEntry  `json:"entry"`
+	Inputs        []GraphPort `json:"inputs"`
+	Outputs       []GraphPort `json:"outputs"`
+	Secrets       []string    `json:"secrets"`
+	Env           []string    `json:"env"`
+	GitHubActions []string    `json:"github_actions"`
+}
+
+type GraphEntry struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// GraphPort is a declared input or output of a graph.
+type GraphPort struct {
+	Id       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Desc     string `json:"desc,omitempty"`
+	Default  any    `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Exec     bool   `json:"exec,omitempty"`
+}
+
+var (
+	reExpression = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	reSecretRef  = regexp.MustCompile(`secrets(?:\.([A-Za-z_][\w-]*)|\[\s*['"]([^'"]+)['"]\s*\])`)
+)
+
+// graphInspector collects the names used by the nodes of a graph and the graphs it references.
+type graphInspector struct {
+	secrets map[string]bool
+	env     map[string]bool
+	actions map[string]bool
+	// Paths of the referenced graphs that are already inspected.
+	refs map[string]bool
+}
+
+// InspectGraph describes the interface of a graph. Unlike `LoadGraph`, it only reads
+// the graph definition, so graphs with GitHub Actions can be inspected anywhere.
+// References to other graph files are resolved against `graphDir`, see `LoadGraphRef`.
+func InspectGraph(graphYaml map[string]any, graphDir string) (GraphInterface, error) {
+	var gi GraphInterface
+
+	inputs, err := LoadGraphInputs(graphYaml)
+	if err != nil {
+		return gi, CreateErr(nil, err, "failed to load graph inputs")
+	}
+	outputs, err := LoadGraphOutputs(graphYaml)
+	if err != nil {
+		return gi, CreateErr(nil, err, "failed to load graph outputs")
+	}
+
+	inputIndexes := map[string]int{}
+	for id, def := range inputs {
+		inputIndexes[string(id)] = def.Index
+	}
+	gi.Inputs = []GraphPort{}
+	for _, id := range sortedPortIds(inputIndexes) {
+		def := inputs[InputId(id)]
+		gi.Inputs = append(gi.Inputs, graphPort(id, def.PortDefinition, def.Default, def.Required))
+	}
+
+	outputIndexes := map[string]int{}
+	for id, def := range outputs {
+		outputIndexes[string(id)] = def.Index
+	}
+	gi.Outputs = []GraphPort{}
+	for _, id := range sortedPortIds(outputIndexes) {
+		def := outputs[OutputId(id)]
+		gi.Outputs = append(gi.Outputs, graphPort(id, def.PortDefinition, nil, false))
+	}
+
+	gi.Entry.Id, _ = graphYaml["entry"].(string)
+	for _, n := range graphNodes(graphYaml) {
+		if id, _ := n["id"].(string); id == gi.Entry.Id {
+			gi.Entry.Type, _ = n["type"].(string)
+		}
+	}
+
+	ins := &graphInspector{
+		secrets: map[string]bool{},
+		env:     map[string]bool{},
+		actions: map[string]bool{},
+		refs:    map[string]bool{},
+	}
+	err = ins.inspect(graphYaml, graphDir)
+	if err != nil {
+		return gi, err
+	}
+
+	gi.Secrets = slices.Sorted(maps.Keys(ins.secrets))
+	gi.Env = slices.Sorted(maps.Keys(ins.env))
+	gi.GitHubActions = slices.Sorted(maps.Keys(ins.actions))
+	return gi, nil
+}
+
+// InspectGraphFromFile loads a graph file and describes its interface.
+func InspectGraphFromFile(graphFile string) (GraphInterface, error) {
+	graphContent, err := os.ReadFile(graphFile)
+	if err != nil {
+		return GraphInterface{}, CreateErr(nil, err, "failed loading graph")
+	}
+
+	graphYaml := make(map[string]any)
+	err = yaml.Unmarshal(graphContent, &graphYaml)
+	if err != nil {
+		return GraphInterface{}, CreateErr(nil, err, "failed to load yaml")
+	}
+
+	return InspectGraph(graphYaml, filepath.Dir(graphFile))
+}
+
+func (ins *graphInspector) inspect(graphYaml map[string]any, graphDir string) error {
+	for _, n := range graphNodes(graphYaml) {
+		nodeType, _ := n["type"].(string)
+		inputs, _ := n["inputs"].(map[string]any)
+
+		// first versions of actionforge had no prefix `core/`
+		if legacyNodeTypeRegex.MatchString(nodeType) {
+			nodeType = "core/" + nodeType
+		}
+
+		switch {
+		case strings.HasPrefix(nodeType, "core/secret@"):
+			if name, ok := inputs["name"].(string); ok && name != "" {
+				ins.secrets[name] = true
+			}
+		case strings.HasPrefix(nodeType, "core/env-get@"):
+			if name, ok := inputs["env"].(string); ok && name != "" {
+				ins.env[name] = true
+			}
+		case strings.HasPrefix(nodeType, "github.com/"):
+			ins.actions[nodeType] = true
+		}
+
+		ins.collectSecretRefs(inputs)
+
+		if subGraph, ok := n["graph"].(map[string]any); ok {
+			err := ins.inspect(subGraph, graphDir)
+			if err != nil {
+				return err
+			}
+		} else if ref, ok := n["ref"].(string); ok {
+			subGraph, graphRef, err := LoadGraphRef(ref, nil, GraphLoadContext{Dir: graphDir})
+			if err != nil {
+				return err
+			}
+			if ins.refs[graphRef.Path] {
+				continue
+			}
+			ins.refs[graphRef.Path] = true
+			err = ins.inspect(subGraph, filepath.Dir(graphRef.Path))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectSecretRefs collects the secrets referenced in expressions like `${{ secrets.TOKEN }}`.
+func (ins *graphInspector) collectSecretRefs(value any) {
+	switch v := value.(type) {
+	case string:
+		for _, expr := range reExpression.FindAllStringSubmatch(v, -1) {
+			for _, m := range reSecretRef.FindAllStringSubmatch(expr[1], -1) {
+				ins.secrets[m[1]+m[2]] = true
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			ins.collectSecretRefs(elem)
+		}
+	case map[string]any:
+		for _, elem := range v {
+			ins.collectSecretRefs(elem)
+		}
+	}
+}
+
+func graphNodes(graphYaml map[string]any) []map[string]any {
+	nodesAny, _ := graphYaml["nodes"].([]any)
+
+	nodes := make([]map[string]any, 0, len(nodesAny))
+	for _, n := range nodesAny {
+		if node, ok := n.(map[string]any); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func graphPort(id string, def PortDefinition, defaultValue any, required bool) GraphPort {
+	typ := def.Type
+	if def.Array {
+		typ = "[]" + typ
+	}
+	return GraphPort{
+		Id:       id,
+		Name:     def.Name,
+		Type:     typ,
+		Desc:     def.Desc,
+		Default:  defaultValue,
+		Required: required,
+		Exec:     def.Exec,
+	}
+}
+
+// sortedPortIds returns the ids of ports in the order of their index.
+func sortedPortIds(indexes map[string]int) []string {
+	return slices.SortedFunc(maps.Keys(indexes), func(a, b string) int {
+		if indexes[a] != indexes[b] {
+			return indexes[a] - indexes[b]
+		}
+		return strings.Compare(a, b)
+	})
+}
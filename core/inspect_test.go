@@ -0,0 +1,35 @@
+package core
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectSecretRefs(t *testing.T) {
+	ins := &graphInspector{secrets: map[string]bool{}}
+	ins.collectSecretRefs(map[string]any{
+		"script":  "deploy --user ${{ secrets.DEPLOY_USER }} --key ${{secrets['SSH_KEY']}}",
+		"env[0]":  "TOKEN=${{ secrets.API-TOKEN }}",
+		"args":    []any{"${{ inputs.target }}", "${{ secrets[\"REGISTRY\"] }}"},
+		"comment": "secrets.NOT_AN_EXPRESSION",
+	})
+
+	assert.Equal(t, []string{"API-TOKEN", "DEPLOY_USER", "REGISTRY", "SSH_KEY"}, slices.Sorted(maps.Keys(ins.secrets)))
+}
+
+func TestInspectLegacyNodeTypes(t *testing.T) {
+	gi, err := InspectGraph(map[string]any{
+		"entry": "start",
+		"nodes": []any{
+			map[string]any{"id": "start", "type": "start@v1"},
+			map[string]any{"id": "secret", "type": "secret@v1", "inputs": map[string]any{"name": "DEPLOY_KEY"}},
+			map[string]any{"id": "env", "type": "env-get@v1", "inputs": map[string]any{"env": "HOME"}},
+		},
+	}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DEPLOY_KEY"}, gi.Secrets)
+	assert.Equal(t, []string{"HOME"}, gi.Env)
+}
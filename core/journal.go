@@ -0,0 +1,493 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+type JournalStatus string
+
+const (
+	JournalStatusRunning   JournalStatus = "running"
+	JournalStatusSucceeded JournalStatus = "succeeded"
+	JournalStatusFailed    JournalStatus = "failed"
+	JournalStatusCancelled JournalStatus = "cancelled"
+)
+
+const journalFileName = "journal.json"
+
+type ResumeBehavior int
+
+const (
+	// The node is skipped if it completed in the resumed run. This is the default.
+	ResumeSkip ResumeBehavior = iota
+
+	// The node only forwards the execution flow, like group nodes.
+	// It isn't recorded in the journal and always runs.
+	ResumeForward
+
+	// The node fires its execution outputs more than once, like loops.
+	// A resumed run has to restart at this node.
+	ResumeRestart
+)
+
+// Nodes implement this interface if they need special treatment when a run is resumed.
+type ResumableNodeInterface interface {
+	GetResumeBehavior() ResumeBehavior
+}
+
+// RunJournal records the progress of a graph run in '<run-dir>/<run-id>/journal.json'.
+// After each execution node that continues with one of its execution outputs, the visited nodes,
+// the serializable output values and the environment changes of that node are written to disk.
+//
+// A resumed run skips all nodes that completed in the previous run, restores their outputs
+// and continues at the first node that didn't complete or can't be resumed.
+//
+// Only the root execution state is journaled. Nodes that run in their own execution state,
+// like the iterations of concurrent loops, are covered by the node that created them.
+type RunJournal struct {
+	RunId       string         `json:"run_id"`
+	GraphFile   string         `json:"graph_file"`
+	GraphSha256 string         `json:"graph_sha256"`
+	Status      JournalStatus  `json:"status"`
+	StartedAt   time.Time      `json:"started_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	ResumeFrom  string         `json:"resume_from,omitempty"`
+	Visited     []ContextVisit `json:"visited"`
+	Nodes       []*JournalNode `json:"nodes"`
+
+	path    string
+	baseEnv map[string]string
+	lock    sync.Mutex
+
+	// Nodes of the journal that is resumed, by full path.
+	resume   map[string]*JournalNode
+	resuming bool
+}
+
+type JournalNode struct {
+	NodeID    string `json:"node_id"`
+	FullPath  string `json:"full_path"`
+	NodeType  string `json:"node_type"`
+	Port      string `json:"port"`
+	Error     string `json:"error,omitempty"`
+	Resumable bool   `json:"resumable"`
+	// Explains why a node can't be resumed.
+	Reason     string                  `json:"reason,omitempty"`
+	Outputs    map[string]JournalValue `json:"outputs,omitempty"`
+	Env        map[string]string       `json:"env,omitempty"`
+	EnvRemoved []string                `json:"env_removed,omitempty"`
+}
+
+// JournalValue is an output value together with its Go type,
+// so it can be restored with the same type it had when it was set.
+type JournalValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// All output value types that can be written to and restored from a journal.
+// Streams, storage providers, credentials and secrets are intentionally missing.
+var journalValueTypes = func() map[string]reflect.Type {
+	m := map[string]reflect.Type{}
+	for _, v := range []any{
+		"", false,
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0),
+		[]string{}, []bool{}, []int{}, []int64{}, []float64{}, []any{},
+		map[string]any{}, map[string]string{},
+		GitRepository{},
+	} {
+		t := reflect.TypeOf(v)
+		m[t.String()] = t
+	}
+	return m
+}()
+
+func encodeJournalValue(v any) (JournalValue, bool) {
+	if v == nil {
+		return JournalValue{}, false
+	}
+
+	t := reflect.TypeOf(v)
+	if _, ok := journalValueTypes[t.String()]; !ok {
+		return JournalValue{}, false
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return JournalValue{}, false
+	}
+
+	return JournalValue{Type: t.String(), Value: b}, true
+}
+
+func (v JournalValue) decode() (any, error) {
+	t, ok := journalValueTypes[v.Type]
+	if !ok {
+		return nil, CreateErr(nil, nil, "unsupported journal value type '%s'", v.Type)
+	}
+
+	ptr := reflect.New(t)
+	err := json.Unmarshal(v.Value, ptr.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+func getResumeBehavior(node NodeBaseInterface) ResumeBehavior {
+	rn, ok := node.(ResumableNodeInterface)
+	if !ok {
+		return ResumeSkip
+	}
+	return rn.GetResumeBehavior()
+}
+
+// OpenRunJournal creates the journal for a new run in `runDir` or, if `resumeRunId` is set,
+// loads the journal of a previous run that is about to be resumed.
+func OpenRunJournal(runDir string, resumeRunId string, graphFile string, graphContent []byte, ec *ExecutionState) (*RunJournal, error) {
+	graphSha256, err := utils.GetSha256OfBytes(graphContent)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &RunJournal{
+		RunId:       ec.Id,
+		GraphFile:   graphFile,
+		GraphSha256: graphSha256,
+		Status:      JournalStatusRunning,
+		StartedAt:   time.Now().UTC(),
+		Nodes:       []*JournalNode{},
+		baseEnv:     ec.GetContextEnvironMapCopy(),
+	}
+
+	if resumeRunId != "" {
+		prev, err := loadRunJournal(filepath.Join(runDir, resumeRunId, journalFileName))
+		if err != nil {
+			return nil, CreateErr(nil, err, "failed to load journal of run '%s'", resumeRunId).
+				SetHint("Check that '--run_dir' points to the same directory that was used for the interrupted run.")
+		}
+
+		if prev.GraphSha256 != graphSha256 {
+			return nil, CreateErr(nil, nil, "graph '%s' has changed since run '%s'", graphFile, resumeRunId).
+				SetHint("A run can only be resumed with the same graph file. Start a new run instead.")
+		}
+
+		if prev.Status == JournalStatusSucceeded {
+			return nil, CreateErr(nil, nil, "run '%s' has already succeeded, there is nothing to resume", resumeRunId)
+		}
+
+		j.RunId = prev.RunId
+		j.StartedAt = prev.StartedAt
+		j.resume = make(map[string]*JournalNode, len(prev.Nodes))
+		for _, jn := range prev.Nodes {
+			j.resume[jn.FullPath] = jn
+		}
+		j.resuming = true
+
+		// the resumed run continues under the id of the interrupted one
+		ec.Id = prev.RunId
+	}
+
+	j.path = filepath.Join(runDir, j.RunId, journalFileName)
+
+	err = os.MkdirAll(filepath.Dir(j.path), 0755)
+	if err != nil {
+		return nil, CreateErr(nil, err, "failed to create run directory")
+	}
+
+	err = j.write()
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func loadRunJournal(path string) (*RunJournal, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var j RunJournal
+	err = json.Unmarshal(b, &j)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (j *RunJournal) GetPath() string {
+	return j.path
+}
+
+// recordHandOver is called when `node` continues with its execution output `port`.
+// At that point the node has set all its outputs, so this is where it counts as completed.
+func (j *RunJournal) recordHandOver(ec *ExecutionState, node NodeBaseInterface, port OutputId, handOverErr error) error {
+	behavior := getResumeBehavior(node)
+	if behavior == ResumeForward {
+		return nil
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	fullPath := node.GetFullPath()
+	for _, jn := range j.Nodes {
+		if jn.FullPath == fullPath {
+			jn.Port = string(port)
+			jn.Resumable = false
+			if jn.Reason == "" {
+				jn.Reason = "node was executed more than once"
+			}
+			return j.writeLocked(ec)
+		}
+	}
+
+	jn := &JournalNode{
+		NodeID:    node.GetId(),
+		FullPath:  fullPath,
+		NodeType:  node.GetNodeTypeId(),
+		Port:      string(port),
+		Resumable: behavior != ResumeRestart,
+		Outputs:   map[string]JournalValue{},
+	}
+
+	if behavior == ResumeRestart {
+		jn.Reason = "node fires its execution outputs repeatedly"
+	}
+
+	if handOverErr != nil {
+		jn.Error = handOverErr.Error()
+	}
+
+	outputs := nodeOutputValues(ec, node)
+	for _, outputId := range slices.Sorted(maps.Keys(outputs)) {
+		jv, ok := encodeJournalValue(outputs[outputId])
+		if !ok {
+			jn.Resumable = false
+			jn.Reason = fmt.Sprintf("output '%s' holds a value of type %T which can't be persisted", outputId, outputs[outputId])
+			continue
+		}
+		if containsSecret(ec, string(jv.Value)) {
+			jn.Resumable = false
+			jn.Reason = fmt.Sprintf("output '%s' holds a secret which isn't persisted", outputId)
+			continue
+		}
+		jn.Outputs[outputId] = jv
+	}
+
+	jn.Env, jn.EnvRemoved = envChanges(j.baseEnv, ec.GetContextEnvironMapCopy())
+
+	// secrets are never written to disk, a resumed run executes the node that set them again
+	if secrets := secretEnvVars(ec, jn.Env); len(secrets) > 0 {
+		for _, k := range secrets {
+			delete(jn.Env, k)
+		}
+		jn.Resumable = false
+		jn.Reason = fmt.Sprintf("environment variable '%s' holds a secret which isn't persisted", secrets[0])
+	}
+
+	j.Nodes = append(j.Nodes, jn)
+	return j.writeLocked(ec)
+}
+
+// nodeOutputValues returns the values of the outputs that `node` has set in `ec`.
+func nodeOutputValues(ec *ExecutionState, node NodeBaseInterface) map[string]any {
+	prefix := node.GetCacheId() + ":"
+
+	ec.OutputCacheLock.RLock()
+	defer ec.OutputCacheLock.RUnlock()
+
+	outputs := map[string]any{}
+	for cacheId, value := range ec.ExecutionOutputCache {
+		if outputId, ok := strings.CutPrefix(cacheId, prefix); ok {
+			outputs[outputId] = value
+		}
+	}
+	return outputs
+}
+
+// envChanges returns the variables of `env` that were added or changed
+// compared to `base`, and the sorted names of the removed ones.
+func envChanges(base map[string]string, env map[string]string) (map[string]string, []string) {
+	var (
+		changed map[string]string
+		removed []string
+	)
+	for k, v := range env {
+		if baseValue, ok := base[k]; !ok || baseValue != v {
+			if changed == nil {
+				changed = map[string]string{}
+			}
+			changed[k] = v
+		}
+	}
+	for k := range base {
+		if _, ok := env[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	slices.Sort(removed)
+	return changed, removed
+}
+
+// secretEnvVars returns the sorted names of the variables in `env` that hold a secret.
+func secretEnvVars(ec *ExecutionState, env map[string]string) []string {
+	var names []string
+	for k, v := range env {
+		if containsSecret(ec, v) {
+			names = append(names, k)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// containsSecret returns true if `s` contains a secret of the run or a masked value.
+func containsSecret(ec *ExecutionState, s string) bool {
+	if utils.ContainsLogMask(s) {
+		return true
+	}
+	for _, secret := range ec.Secrets {
+		if strings.TrimSpace(secret) != "" && strings.Contains(s, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEnvChanges returns a copy of `base` with the changes from `envChanges` applied.
+func applyEnvChanges(base map[string]string, changed map[string]string, removed []string) map[string]string {
+	env := maps.Clone(base)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, changed)
+	for _, k := range removed {
+		delete(env, k)
+	}
+	return env
+}
+
+// nodeToSkip returns the journal entry of the resumed run if `node` can be skipped.
+// The first node that can't be skipped ends the resume, all subsequent nodes run as usual.
+func (j *RunJournal) nodeToSkip(node NodeBaseInterface) (*JournalNode, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if !j.resuming {
+		return nil, false
+	}
+
+	behavior := getResumeBehavior(node)
+	if behavior == ResumeForward {
+		return nil, false
+	}
+
+	jn, ok := j.resume[node.GetFullPath()]
+	if !ok || !jn.Resumable || behavior == ResumeRestart {
+		j.resuming = false
+		utils.LogOut.Infof("▶️ Resume run '%s' at '%s (%s)'\n", j.RunId, node.GetName(), node.GetId())
+		return nil, false
+	}
+
+	return jn, true
+}
+
+// skipNode restores the outputs and environment of a node that completed in the resumed run
+// and continues with the execution output the node took back then.
+func (j *RunJournal) skipNode(ec *ExecutionState, node NodeBaseAndExecutionInterface, jn *JournalNode) error {
+	utils.LogOut.Infof("⏭️ Skip '%s (%s)', completed in run '%s'\n", node.GetName(), node.GetId(), j.RunId)
+
+	for outputId, jv := range jn.Outputs {
+		value, err := jv.decode()
+		if err != nil {
+			return CreateErr(ec, err, "failed to restore output '%s' of node '%s'", outputId, jn.FullPath)
+		}
+		ec.CacheDataOutput(node.GetCacheId(), outputId, value, Permanent)
+	}
+
+	ec.SetContextEnvironMap(applyEnvChanges(j.baseEnv, jn.Env, jn.EnvRemoved))
+
+	var err error
+	if jn.Error != "" {
+		err = &CauseError{Message: jn.Error}
+	}
+
+	return node.Execute(OutputId(jn.Port), ec, err)
+}
+
+// Finish writes the final status of the run.
+func (j *RunJournal) Finish(ec *ExecutionState, runErr error) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.Status = getRunStatus(ec, runErr)
+	return j.writeLocked(ec)
+}
+
+// getRunStatus returns the final status of a run that ended with `runErr`.
+func getRunStatus(ec *ExecutionState, runErr error) JournalStatus {
+	switch {
+	case runErr == nil:
+		return JournalStatusSucceeded
+	case ec.IsCancelled() || errors.Is(runErr, ec.Ctx.Err()):
+		return JournalStatusCancelled
+	default:
+		return JournalStatusFailed
+	}
+}
+
+func (j *RunJournal) writeLocked(ec *ExecutionState) error {
+	ec.ContextStackLock.RLock()
+	j.Visited = slices.Clone(ec.Visited)
+	ec.ContextStackLock.RUnlock()
+
+	j.ResumeFrom = ""
+	for _, jn := range j.Nodes {
+		if !jn.Resumable {
+			j.ResumeFrom = jn.FullPath
+			break
+		}
+	}
+
+	return j.write()
+}
+
+// write replaces the journal file atomically, so an interrupted
+// write never leaves a truncated journal behind.
+func (j *RunJournal) write() error {
+	j.UpdatedAt = time.Now().UTC()
+
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return CreateErr(nil, err, "failed to serialize run journal")
+	}
+
+	tmp := j.path + ".tmp"
+	err = os.WriteFile(tmp, b, 0644)
+	if err != nil {
+		return CreateErr(nil, err, "failed to write run journal")
+	}
+
+	err = os.Rename(tmp, j.path)
+	if err != nil {
+		return CreateErr(nil, err, "failed to write run journal")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalValue_RoundTrip(t *testing.T) {
+	values := []any{
+		"hello",
+		true,
+		42,
+		int64(-7),
+		3.5,
+		[]string{"a", "b"},
+		[]bool{true, false},
+		map[string]any{"key": "value"},
+		GitRepository{Path: "/tmp/repo"},
+	}
+
+	for _, v := range values {
+		jv, ok := encodeJournalValue(v)
+		assert.True(t, ok, "%T should be serializable", v)
+
+		restored, err := jv.decode()
+		assert.NoError(t, err)
+		assert.Equal(t, v, restored)
+	}
+}
+
+func TestJournalValue_NotSerializable(t *testing.T) {
+	values := []any{
+		DataStreamFactory{Reader: strings.NewReader("stream")},
+		io.NopCloser(strings.NewReader("stream")),
+		SecretValue{Secret: "hunter2"},
+		nil,
+	}
+
+	for _, v := range values {
+		_, ok := encodeJournalValue(v)
+		assert.False(t, ok, "%T must not be serializable", v)
+	}
+}
+
+func TestSecretEnvVars(t *testing.T) {
+	ec := &ExecutionState{Secrets: map[string]string{"TOKEN": "hunter2", "EMPTY": ""}}
+
+	env := map[string]string{
+		"AUTH":   "Bearer hunter2",
+		"PLAIN":  "hello",
+		"EMPTY":  "",
+		"SECRET": "hunter2",
+	}
+	assert.Equal(t, []string{"AUTH", "SECRET"}, secretEnvVars(ec, env))
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Node types of the loops that loop-break and loop-continue nodes can signal.
+var loopNodeTypes = []string{
+	"core/for-loop@",
+	"core/for-each-loop@",
+	"core/concurrent-for-loop@",
+	"core/concurrent-for-each-loop@",
+	"core/while-loop@",
+}
+
+// LoopSignal is returned by loop-break and loop-continue nodes. It travels up
+// the nodes of the loop body like an error until it reaches the loop it's meant for.
+type LoopSignal struct {
+	// The loop that is broken or continued.
+	Loop NodeBaseInterface
+	// The loop-break or loop-continue node that sent the signal.
+	Source NodeBaseInterface
+	// True for break, false for continue.
+	Break bool
+}
+
+func (s *LoopSignal) Error() string {
+	action := "continue"
+	if s.Break {
+		action = "break"
+	}
+	return fmt.Sprintf("unhandled %s of loop '%s' from node '%s'", action, s.Loop.GetId(), s.Source.GetId())
+}
+
+func IsLoopNode(node NodeBaseInterface) bool {
+	for _, prefix := range loopNodeTypes {
+		if strings.HasPrefix(node.GetNodeTypeId(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func IsLoopSignal(err error) bool {
+	var signal *LoopSignal
+	return errors.As(err, &signal)
+}
+
+// NewLoopSignal creates the signal of `source` for the loop with the id `loopId`,
+// or for the nearest loop if `loopId` is empty. The loop is looked up in the hierarchy
+// of the execution state, which contains the loops whose body is currently executed.
+func NewLoopSignal(c *ExecutionState, source NodeBaseInterface, loopId string, brk bool) error {
+	for i := len(c.Hierarchy) - 1; i >= 0; i-- {
+		node := c.Hierarchy[i]
+
+		if IsLoopNode(node) {
+			if loopId == "" || node.GetId() == loopId {
+				return &LoopSignal{
+					Loop:   node,
+					Source: source,
+					Break:  brk,
+				}
+			}
+			continue
+		}
+
+		// Other nodes than groups and loops in the hierarchy started a concurrent execution.
+		// Its branches can't be broken or continued as a whole, only loops inside of them.
+		if !strings.HasPrefix(node.GetNodeTypeId(), "core/group@") {
+			return CreateErr(c, nil, "node '%s' can't signal a loop outside of the concurrent execution of node '%s'", source.GetId(), node.GetId()).
+				SetHint("place the loop inside the branch of '%s'", node.GetId())
+		}
+	}
+
+	if loopId != "" {
+		return CreateErr(c, nil, "node '%s' is not inside the body of loop '%s'", source.GetId(), loopId).
+			SetHint("use the id of a loop whose body executes this node, or leave it empty to signal the nearest loop")
+	}
+	return CreateErr(c, nil, "node '%s' is not inside the body of a loop", source.GetId()).
+		SetHint("connect the node to a node that is executed by the body of a loop")
+}
+
+// LoopSignalFor returns the loop signal in `err` if it's meant for `loop`.
+func LoopSignalFor(loop NodeBaseInterface, err error) (*LoopSignal, bool) {
+	var signal *LoopSignal
+	if err != nil && errors.As(err, &signal) && signal.Loop == loop {
+		return signal, true
+	}
+	return nil, false
+}
+
+// EnterLoop adds `loop` to the hierarchy while its body is executed in `c`.
+// The returned function restores the previous hierarchy.
+func (c *ExecutionState) EnterLoop(loop NodeBaseInterface) func() {
+	hierarchy := c.Hierarchy
+	c.Hierarchy = append(slices.Clone(hierarchy), loop)
+	return func() {
+		c.Hierarchy = hierarchy
+	}
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+// MemoEntry is the result of a node with `cache: true`, stored in '<cache-dir>/<key>.json'.
+// The key is a hash of the node type, the resolved input values of the node and the
+// contents of the files its inputs refer to. If a later run executes a node with the
+// same key, the node is skipped and its outputs and environment changes are restored.
+//
+//   - id: render
+//     type: core/run@v1
+//     cache: true
+type MemoEntry struct {
+	NodeType   string                  `json:"node_type"`
+	Port       string                  `json:"port"`
+	Outputs    map[string]JournalValue `json:"outputs,omitempty"`
+	Env        map[string]string       `json:"env,omitempty"`
+	EnvRemoved []string                `json:"env_removed,omitempty"`
+}
+
+// nodeMemo tracks the execution of a cached node until it hands over to one of its execution outputs.
+type nodeMemo struct {
+	node NodeBaseInterface
+	path string
+	env  map[string]string
+	hit  *MemoEntry
+}
+
+// LoadMemoize reads the node-level `cache` setting from a node definition.
+func LoadMemoize(node NodeBaseInterface, nodeI map[string]any) (bool, error) {
+	v, ok := nodeI["cache"]
+	if !ok {
+		return false, nil
+	}
+
+	cache, ok := v.(bool)
+	if !ok {
+		return false, CreateErr(nil, nil, "'cache' must be a boolean, got '%v'", v)
+	}
+
+	if cache && getResumeBehavior(node) != ResumeSkip {
+		return false, CreateErr(nil, nil, "nodes of type '%s' can't be cached", node.GetNodeTypeId()).
+			SetHint("only nodes that execute one of their execution outputs exactly once can be cached")
+	}
+	return cache, nil
+}
+
+// DefaultMemoDir returns the directory that node results are cached in if no cache directory
+// is set, e.g. '~/.cache/actrun/nodes' on Linux.
+func DefaultMemoDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", CreateErr(nil, err, "unable to get user cache directory")
+	}
+	return filepath.Join(cacheDir, "actrun", "nodes"), nil
+}
+
+// lookupMemo returns the memo of `node` if it has `cache: true`. If the result of the node
+// is already cached, it's available in the `hit` field. If the key of the node can't be
+// computed, e.g. because an input holds a stream, the node runs without being cached.
+func (c *ExecutionState) lookupMemo(node NodeBaseInterface) *nodeMemo {
+	if !node.IsMemoized() {
+		return nil
+	}
+
+	key, err := memoKey(c, node)
+	if err != nil {
+		utils.LogErr.Warnf("node '%s' (%s) is executed without its cache: %s\n", node.GetName(), node.GetId(), err)
+		return nil
+	}
+
+	dir := c.MemoDir
+	if dir == "" {
+		dir, err = DefaultMemoDir()
+		if err != nil {
+			utils.LogErr.Warnf("node '%s' (%s) is executed without its cache: %s\n", node.GetName(), node.GetId(), err)
+			return nil
+		}
+	}
+
+	memo := &nodeMemo{
+		node: node,
+		path: filepath.Join(dir, key+".json"),
+		env:  c.GetContextEnvironMapCopy(),
+	}
+
+	b, err := os.ReadFile(memo.path)
+	if err == nil {
+		var entry MemoEntry
+		if json.Unmarshal(b, &entry) == nil && entry.NodeType == node.GetNodeTypeId() {
+			memo.hit = &entry
+		}
+	}
+
+	return memo
+}
+
+// memoKey hashes everything a node's result depends on, apart from the environment.
+func memoKey(c *ExecutionState, node NodeBaseInterface) (string, error) {
+	key := struct {
+		NodeType string                  `json:"node_type"`
+		Inputs   map[string]JournalValue `json:"inputs"`
+		Files    map[string]string       `json:"files"`
+	}{
+		NodeType: node.GetNodeTypeId(),
+		Inputs:   map[string]JournalValue{},
+		Files:    map[string]string{},
+	}
+
+	inputNode, ok := node.(NodeWithInputs)
+	if ok {
+		inputs := map[string]any{}
+
+		for inputId, inputDef := range inputNode.GetInputDefs() {
+			if inputDef.Exec || inputDef.Array {
+				continue
+			}
+			v, err := inputNode.InputValueById(c, inputNode, inputId, nil)
+			if errors.Is(err, &ErrNoInputValue{}) {
+				continue
+			} else if err != nil {
+				return "", err
+			} else if v == nil {
+				continue
+			}
+			inputs[string(inputId)] = v
+		}
+
+		for _, indexPort := range inputNode.GetInputIndexPorts() {
+			arrayPortId := InputId(indexPort.ArrayPortId)
+			v, err := inputNode.InputValueById(c, inputNode, InputId(indexPort.IndexPortId), &arrayPortId)
+			if errors.Is(err, &ErrNoInputValue{}) {
+				continue
+			} else if err != nil {
+				return "", err
+			} else if v == nil {
+				continue
+			}
+			inputs[indexPort.IndexPortId] = v
+		}
+
+		for _, inputId := range slices.Sorted(maps.Keys(inputs)) {
+			jv, ok := encodeJournalValue(inputs[inputId])
+			if !ok {
+				return "", fmt.Errorf("input '%s' holds a value of type %T which can't be hashed", inputId, inputs[inputId])
+			}
+			key.Inputs[inputId] = jv
+
+			var paths []string
+			switch v := inputs[inputId].(type) {
+			case string:
+				paths = []string{v}
+			case []string:
+				paths = v
+			case []any:
+				for _, elem := range v {
+					if path, ok := elem.(string); ok {
+						paths = append(paths, path)
+					}
+				}
+			}
+
+			// inputs that refer to files depend on their contents
+			for _, path := range paths {
+				fi, err := os.Stat(path)
+				if err != nil || !fi.Mode().IsRegular() {
+					continue
+				}
+				fileHash, err := utils.GetSha256OfFile(path)
+				if err != nil {
+					return "", err
+				}
+				key.Files[path] = fileHash
+			}
+		}
+	}
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// store writes the result of the node when it hands over to its execution output `port`.
+// Nodes that fail or set outputs that can't be persisted aren't cached, and neither are
+// nodes whose outputs or environment changes hold a secret.
+func (m *nodeMemo) store(c *ExecutionState, port OutputId, handOverErr error) {
+	if handOverErr != nil {
+		return
+	}
+
+	entry := MemoEntry{
+		NodeType: m.node.GetNodeTypeId(),
+		Port:     string(port),
+		Outputs:  map[string]JournalValue{},
+	}
+
+	outputs := nodeOutputValues(c, m.node)
+	for _, outputId := range slices.Sorted(maps.Keys(outputs)) {
+		jv, ok := encodeJournalValue(outputs[outputId])
+		if !ok {
+			utils.LogErr.Warnf("node '%s' (%s) isn't cached, output '%s' holds a value of type %T which can't be persisted\n",
+				m.node.GetName(), m.node.GetId(), outputId, outputs[outputId])
+			return
+		}
+		if containsSecret(c, string(jv.Value)) {
+			utils.LogErr.Warnf("node '%s' (%s) isn't cached, output '%s' holds a secret\n", m.node.GetName(), m.node.GetId(), outputId)
+			return
+		}
+		entry.Outputs[outputId] = jv
+	}
+
+	entry.Env, entry.EnvRemoved = envChanges(m.env, c.GetContextEnvironMapCopy())
+	if secrets := secretEnvVars(c, entry.Env); len(secrets) > 0 {
+		utils.LogErr.Warnf("node '%s' (%s) isn't cached, environment variable '%s' holds a secret\n", m.node.GetName(), m.node.GetId(), secrets[0])
+		return
+	}
+
+	b, err := json.MarshalIndent(entry, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(m.path), 0755)
+	}
+	if err == nil {
+		// write to a temporary file first, so concurrent runs never read a partial entry
+		tmp := fmt.Sprintf("%s.%d.tmp", m.path, os.Getpid())
+		err = os.WriteFile(tmp, b, 0644)
+		if err == nil {
+			err = os.Rename(tmp, m.path)
+		}
+	}
+	if err != nil {
+		utils.LogErr.Warnf("failed to cache the result of node '%s' (%s): %s\n", m.node.GetName(), m.node.GetId(), err)
+	}
+}
+
+// restore skips the node and continues with the execution output of the cached result.
+func (m *nodeMemo) restore(c *ExecutionState, node NodeBaseAndExecutionInterface) error {
+	utils.LogOut.Infof("♻️ Skip '%s (%s)', result is cached\n", node.GetName(), node.GetId())
+
+	for outputId, jv := range m.hit.Outputs {
+		value, err := jv.decode()
+		if err != nil {
+			return CreateErr(c, err, "failed to restore cached output '%s' of node '%s'", outputId, node.GetFullPath())
+		}
+		c.CacheDataOutput(node.GetCacheId(), outputId, value, Permanent)
+	}
+
+	c.SetContextEnvironMap(applyEnvChanges(m.env, m.hit.Env, m.hit.EnvRemoved))
+
+	return node.Execute(OutputId(m.hit.Port), c, nil)
+}
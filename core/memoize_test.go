@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoTestNode struct {
+	NodeBaseComponent
+	Inputs
+}
+
+func TestMemoKey(t *testing.T) {
+	dir := t.TempDir()
+	scene := filepath.Join(dir, "scene.txt")
+	assert.NoError(t, os.WriteFile(scene, []byte("frame 1"), 0644))
+
+	c := &ExecutionState{Ctx: context.Background()}
+
+	newNode := func(path string, quality int) *memoTestNode {
+		n := &memoTestNode{}
+		n.SetNodeType("core/test@v1")
+		n.SetInputDefs(map[InputId]InputDefinition{
+			"exec":    {PortDefinition: PortDefinition{Exec: true}},
+			"path":    {PortDefinition: PortDefinition{Type: "string"}},
+			"quality": {PortDefinition: PortDefinition{Type: "number"}},
+		}, SetDefsOpts{})
+		assert.NoError(t, n.SetInputValue("path", path))
+		assert.NoError(t, n.SetInputValue("quality", quality))
+		return n
+	}
+
+	key, err := memoKey(c, newNode(scene, 1))
+	assert.NoError(t, err)
+
+	same, err := memoKey(c, newNode(scene, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	otherInput, err := memoKey(c, newNode(scene, 2))
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherInput)
+
+	assert.NoError(t, os.WriteFile(scene, []byte("frame 2"), 0644))
+	otherFile, err := memoKey(c, newNode(scene, 1))
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherFile)
+}
+
+func TestEnvChanges(t *testing.T) {
+	base := map[string]string{"PATH": "/bin", "HOME": "/root", "TMP": "/tmp"}
+	env := map[string]string{"PATH": "/opt/bin:/bin", "HOME": "/root", "FRAMES": "2"}
+
+	changed, removed := envChanges(base, env)
+	assert.Equal(t, map[string]string{"PATH": "/opt/bin:/bin", "FRAMES": "2"}, changed)
+	assert.Equal(t, []string{"TMP"}, removed)
+	assert.Equal(t, env, applyEnvChanges(base, changed, removed))
+}
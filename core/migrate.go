@@ -0,0 +1,429 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+
+	"github.com/actionforge/actrun-cli/utils"
+	"github.com/pmezard/go-difflib/difflib"
+	"go.yaml.in/yaml/v4"
+)
+
+// MigrationFunc changes a node definition of the old node type so that it is valid for
+// the new node type. The type of the node is updated after the function returns.
+type MigrationFunc func(m *NodeMigration) error
+
+type nodeMigrationEntry struct {
+	to string
+	fn MigrationFunc
+}
+
+var nodeMigrations = make(map[string]nodeMigrationEntry)
+
+// first versions of actionforge had no prefix `core/`
+var legacyNodeTypeRegex = regexp.MustCompile(`^[\w-]+@v1$`)
+
+// RegisterNodeMigration registers the migration of nodes of type `from`, e.g. `core/run@v1`,
+// to type `to`, e.g. `core/run@v2`. Migrations are chained, so a node is migrated until
+// its type has no migration anymore.
+func RegisterNodeMigration(from string, to string, fn MigrationFunc) error {
+	if from == to {
+		return CreateErr(nil, nil, "migration of node type '%s' must change the node type", from)
+	}
+
+	_, ok := nodeMigrations[from]
+	if ok {
+		return CreateErr(nil, nil, "migration of node type '%s' already registered", from)
+	}
+
+	nodeMigrations[from] = nodeMigrationEntry{to: to, fn: fn}
+	return nil
+}
+
+// MigrationStep describes the migration of a single node from one node type to the next.
+type MigrationStep struct {
+	// Full path of the node, with the ids of its groups.
+	Node string
+	From string
+	To   string
+}
+
+// NodeMigration is passed to a MigrationFunc with the node to migrate and the graph it belongs to.
+// Its methods update the connections and executions of the graph together with the node.
+type NodeMigration struct {
+	Graph map[string]any
+	Node  map[string]any
+}
+
+func (m *NodeMigration) NodeId() string {
+	id, _ := m.Node["id"].(string)
+	return id
+}
+
+// RenameInput renames an input of the node, including its value, its index ports and
+// the connections or executions to it.
+func (m *NodeMigration) RenameInput(from InputId, to InputId) {
+	renamePortValues(m.Node, "inputs", string(from), string(to))
+	renamePortLinks(m.Graph, "dst", m.NodeId(), string(from), m.NodeId(), string(to))
+}
+
+// RenameOutput renames an output of the node, including its index ports and the
+// connections or executions from it.
+func (m *NodeMigration) RenameOutput(from OutputId, to OutputId) {
+	renamePortValues(m.Node, "outputs", string(from), string(to))
+	renamePortLinks(m.Graph, "src", m.NodeId(), string(from), m.NodeId(), string(to))
+}
+
+// RenameOption changes the value of an option input if it is set to `from`.
+func (m *NodeMigration) RenameOption(input InputId, from string, to string) {
+	inputs, _ := m.Node["inputs"].(map[string]any)
+	if v, ok := inputs[string(input)].(string); ok && v == from {
+		inputs[string(input)] = to
+	}
+}
+
+// AddNode adds a node to the graph, e.g. to split the migrated node into several nodes.
+// Added nodes are migrated too if their type has a migration.
+func (m *NodeMigration) AddNode(node map[string]any) {
+	nodes, _ := m.Graph["nodes"].([]any)
+	m.Graph["nodes"] = append(nodes, node)
+}
+
+// MoveInput moves an input of the node to another node, including its value and
+// the connections or executions to it.
+func (m *NodeMigration) MoveInput(from InputId, dstNode string, to InputId) {
+	moveIndexedValues(m.Node, m.findNode(dstNode), "inputs", string(from), string(to))
+	renamePortLinks(m.Graph, "dst", m.NodeId(), string(from), dstNode, string(to))
+}
+
+// MoveOutput moves an output of the node to another node, including the connections
+// or executions from it.
+func (m *NodeMigration) MoveOutput(from OutputId, srcNode string, to OutputId) {
+	moveIndexedValues(m.Node, m.findNode(srcNode), "outputs", string(from), string(to))
+	renamePortLinks(m.Graph, "src", m.NodeId(), string(from), srcNode, string(to))
+}
+
+// AddConnection adds a data connection between two nodes of the graph.
+func (m *NodeMigration) AddConnection(srcNode string, srcPort OutputId, dstNode string, dstPort InputId) {
+	addPortLink(m.Graph, "connections", srcNode, string(srcPort), dstNode, string(dstPort))
+}
+
+// AddExecution adds an execution connection between two nodes of the graph.
+func (m *NodeMigration) AddExecution(srcNode string, srcPort OutputId, dstNode string, dstPort InputId) {
+	addPortLink(m.Graph, "executions", srcNode, string(srcPort), dstNode, string(dstPort))
+}
+
+func (m *NodeMigration) findNode(id string) map[string]any {
+	for _, n := range graphNodes(m.Graph) {
+		if nodeId, _ := n["id"].(string); nodeId == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// MigrateGraph migrates the nodes of a graph whose node types have a registered migration
+// and returns the migration steps. The graph is changed in place. If `recursive` is set,
+// the inline graphs of groups are migrated too.
+func MigrateGraph(graphYaml map[string]any, recursive bool) ([]MigrationStep, error) {
+	return migrateGraph(graphYaml, "", recursive)
+}
+
+func migrateGraph(graphYaml map[string]any, parentId string, recursive bool) ([]MigrationStep, error) {
+	var steps []MigrationStep
+
+	// nodes added by migrations are appended and migrated in the same loop
+	for i := 0; i < len(graphNodes(graphYaml)); i++ {
+		node := graphNodes(graphYaml)[i]
+
+		id, _ := node["id"].(string)
+		fullPath := id
+		if parentId != "" {
+			fullPath = parentId + "/" + id
+		}
+
+		nodeSteps, err := migrateNode(graphYaml, node, fullPath)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, nodeSteps...)
+
+		if subGraph, ok := node["graph"].(map[string]any); ok && recursive {
+			subSteps, err := migrateGraph(subGraph, fullPath, recursive)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, subSteps...)
+		}
+	}
+
+	return steps, nil
+}
+
+func migrateNode(graphYaml map[string]any, node map[string]any, fullPath string) ([]MigrationStep, error) {
+	var steps []MigrationStep
+
+	nodeType, _ := node["type"].(string)
+	if legacyNodeTypeRegex.MatchString(nodeType) {
+		steps = append(steps, MigrationStep{Node: fullPath, From: nodeType, To: "core/" + nodeType})
+		nodeType = "core/" + nodeType
+		node["type"] = nodeType
+	}
+
+	visited := map[string]bool{}
+	for {
+		migration, ok := nodeMigrations[nodeType]
+		if !ok {
+			break
+		}
+		if visited[nodeType] {
+			return nil, CreateErr(nil, nil, "migrations of node type '%s' form a cycle", nodeType)
+		}
+		visited[nodeType] = true
+
+		if migration.fn != nil {
+			err := migration.fn(&NodeMigration{Graph: graphYaml, Node: node})
+			if err != nil {
+				return nil, CreateErr(nil, err, "failed to migrate node '%s' from '%s' to '%s'", fullPath, nodeType, migration.to)
+			}
+		}
+
+		steps = append(steps, MigrationStep{Node: fullPath, From: nodeType, To: migration.to})
+		nodeType = migration.to
+		node["type"] = nodeType
+	}
+
+	return steps, nil
+}
+
+// migrateLoadedGraph migrates the nodes of a graph that is loaded for execution or validation,
+// and warns about the deprecated node types. Groups migrate their own graph when they are loaded.
+func migrateLoadedGraph(graphYaml map[string]any, parentId string) error {
+	steps, err := migrateGraph(graphYaml, parentId, false)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		utils.LogErr.Warnf("node '%s' uses the deprecated node type '%s', it is loaded as '%s'. Run 'actrun migrate' to update the graph file.\n",
+			step.Node, step.From, step.To)
+	}
+	return nil
+}
+
+// MigrateGraphFile migrates the nodes of a graph file and returns the migration steps and a
+// unified diff of the changes. Unless `dryRun` is set, the file is rewritten. The order of
+// keys and the comments of the file are kept.
+func MigrateGraphFile(graphFile string, dryRun bool) ([]MigrationStep, string, error) {
+	graphContent, err := os.ReadFile(graphFile)
+	if err != nil {
+		return nil, "", CreateErr(nil, err, "failed loading graph")
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(graphContent, &doc)
+	if err != nil {
+		return nil, "", CreateErr(nil, err, "failed to load yaml")
+	}
+
+	graphYaml := make(map[string]any)
+	err = doc.Decode(&graphYaml)
+	if err != nil {
+		return nil, "", CreateErr(nil, err, "failed to load yaml")
+	}
+
+	steps, err := MigrateGraph(graphYaml, true)
+	if err != nil || len(steps) == 0 {
+		return steps, "", err
+	}
+
+	err = syncYamlNode(&doc, graphYaml)
+	if err != nil {
+		return nil, "", CreateErr(nil, err, "failed to update graph")
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return nil, "", CreateErr(nil, err, "failed to write yaml")
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(graphContent)),
+		B:        difflib.SplitLines(buf.String()),
+		FromFile: graphFile,
+		ToFile:   graphFile,
+		Context:  3,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !dryRun {
+		err = os.WriteFile(graphFile, buf.Bytes(), 0644)
+		if err != nil {
+			return nil, "", CreateErr(nil, err, "failed to write graph file")
+		}
+	}
+	return steps, diff, nil
+}
+
+// syncYamlNode updates a yaml node to a decoded value, but only replaces the parts that changed.
+// New keys of a mapping are appended in sorted order.
+func syncYamlNode(node *yaml.Node, value any) error {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		return syncYamlNode(node.Content[0], value)
+	}
+
+	var current any
+	err := node.Decode(&current)
+	if err == nil && reflect.DeepEqual(current, value) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+
+		var content []*yaml.Node
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			elem, ok := v[key]
+			if !ok {
+				continue
+			}
+			seen[key] = true
+			err = syncYamlNode(node.Content[i+1], elem)
+			if err != nil {
+				return err
+			}
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if seen[key] {
+				continue
+			}
+			var keyNode, valueNode yaml.Node
+			keyNode.SetString(key)
+			err = valueNode.Encode(v[key])
+			if err != nil {
+				return err
+			}
+			content = append(content, &keyNode, &valueNode)
+		}
+		node.Content = content
+		return nil
+	case []any:
+		if node.Kind != yaml.SequenceNode {
+			break
+		}
+
+		content := node.Content[:min(len(node.Content), len(v))]
+		for i := range content {
+			err = syncYamlNode(content[i], v[i])
+			if err != nil {
+				return err
+			}
+		}
+		for _, elem := range v[len(content):] {
+			var elemNode yaml.Node
+			err = elemNode.Encode(elem)
+			if err != nil {
+				return err
+			}
+			content = append(content, &elemNode)
+		}
+		node.Content = content
+		return nil
+	}
+
+	var replacement yaml.Node
+	err = replacement.Encode(value)
+	if err != nil {
+		return err
+	}
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+	*node = replacement
+	return nil
+}
+
+// renamePortValues renames the entry of a port and its index ports in the `inputs` or `outputs` of a node.
+func renamePortValues(node map[string]any, section string, from string, to string) {
+	moveIndexedValues(node, node, section, from, to)
+}
+
+// moveIndexedValues moves the entry of a port and its index ports from the `inputs` or `outputs`
+// of one node to another.
+func moveIndexedValues(src map[string]any, dst map[string]any, section string, from string, to string) {
+	srcValues, _ := src[section].(map[string]any)
+
+	moved := map[string]any{}
+	for _, key := range slices.Sorted(maps.Keys(srcValues)) {
+		newKey, ok := renamePortId(key, from, to)
+		if ok {
+			moved[newKey] = srcValues[key]
+			delete(srcValues, key)
+		}
+	}
+	if len(moved) == 0 || dst == nil {
+		return
+	}
+
+	dstValues, ok := dst[section].(map[string]any)
+	if !ok {
+		dstValues = map[string]any{}
+		dst[section] = dstValues
+	}
+	maps.Copy(dstValues, moved)
+}
+
+// renamePortLinks renames the `src` or `dst` ports of the connections and executions of a graph.
+func renamePortLinks(graphYaml map[string]any, end string, fromNode string, fromPort string, toNode string, toPort string) {
+	for _, section := range []string{"connections", "executions"} {
+		links, _ := graphYaml[section].([]any)
+		for _, link := range links {
+			port, _ := utils.GetTypedPropertyByPath[map[string]any](link.(map[string]any), end)
+			if port == nil || port["node"] != fromNode {
+				continue
+			}
+			portId, _ := port["port"].(string)
+			newPortId, ok := renamePortId(portId, fromPort, toPort)
+			if ok {
+				port["node"] = toNode
+				port["port"] = newPortId
+			}
+		}
+	}
+}
+
+// renamePortId renames a port id or an index port id of it, like `values[0]`.
+func renamePortId(portId string, from string, to string) (string, bool) {
+	if portId == from {
+		return to, true
+	}
+	arrayPortId, index, ok := IsValidIndexPortId(portId)
+	if ok && arrayPortId == from {
+		return fmt.Sprintf("%s[%d]", to, index), true
+	}
+	return portId, false
+}
+
+func addPortLink(graphYaml map[string]any, section string, srcNode string, srcPort string, dstNode string, dstPort string) {
+	links, _ := graphYaml[section].([]any)
+	graphYaml[section] = append(links, map[string]any{
+		"src": map[string]any{"node": srcNode, "port": srcPort},
+		"dst": map[string]any{"node": dstNode, "port": dstPort},
+	})
+}
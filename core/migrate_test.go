@@ -0,0 +1,96 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.yaml.in/yaml/v4"
+)
+
+const migrateTestGraph = `
+entry: start
+nodes:
+  - id: start
+    type: core/start@v1
+  - id: fmt
+    type: core/test-fmt@v1
+    inputs:
+      format: '%v'
+      mode: loud
+      args[0]: null
+connections:
+  - src:
+      node: value
+      port: result
+    dst:
+      node: fmt
+      port: args[0]
+executions:
+  - src:
+      node: start
+      port: exec
+    dst:
+      node: fmt
+      port: exec
+`
+
+func TestMigrateGraph(t *testing.T) {
+	// v1 -> v2 renames ports and an option value, v2 -> v3 splits the printing into its own node
+	assert.NoError(t, RegisterNodeMigration("core/test-fmt@v1", "core/test-fmt@v2", func(m *NodeMigration) error {
+		m.RenameInput("args", "values")
+		m.RenameInput("format", "fmt")
+		m.RenameOption("mode", "loud", "upper")
+		return nil
+	}))
+	assert.NoError(t, RegisterNodeMigration("core/test-fmt@v2", "core/test-fmt@v3", func(m *NodeMigration) error {
+		m.AddNode(map[string]any{"id": m.NodeId() + "-print", "type": "print@v1"})
+		m.MoveInput("mode", m.NodeId()+"-print", "color")
+		m.AddConnection(m.NodeId(), "result", m.NodeId()+"-print", "values[0]")
+		return nil
+	}))
+	t.Cleanup(func() {
+		delete(nodeMigrations, "core/test-fmt@v1")
+		delete(nodeMigrations, "core/test-fmt@v2")
+	})
+
+	assert.Error(t, RegisterNodeMigration("core/test-fmt@v1", "core/test-fmt@v4", nil))
+
+	var graphYaml map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(migrateTestGraph), &graphYaml))
+
+	steps, err := MigrateGraph(graphYaml, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []MigrationStep{
+		{Node: "fmt", From: "core/test-fmt@v1", To: "core/test-fmt@v2"},
+		{Node: "fmt", From: "core/test-fmt@v2", To: "core/test-fmt@v3"},
+		{Node: "fmt-print", From: "print@v1", To: "core/print@v1"},
+	}, steps)
+
+	nodes := graphNodes(graphYaml)
+	assert.Equal(t, map[string]any{"fmt": "%v", "values[0]": nil}, nodes[1]["inputs"])
+	assert.Equal(t, map[string]any{"color": "upper"}, nodes[2]["inputs"])
+
+	connections := graphYaml["connections"].([]any)
+	assert.Equal(t, map[string]any{"node": "fmt", "port": "values[0]"}, connections[0].(map[string]any)["dst"])
+	assert.Equal(t, map[string]any{"node": "fmt-print", "port": "values[0]"}, connections[1].(map[string]any)["dst"])
+
+	// the migrated graph has nothing left to migrate
+	steps, err = MigrateGraph(graphYaml, true)
+	assert.NoError(t, err)
+	assert.Empty(t, steps)
+}
+
+func TestSyncYamlNode(t *testing.T) {
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte("# graph\nb: 1\na: [x, y]  # list\nc: keep\n"), &doc))
+
+	assert.NoError(t, syncYamlNode(&doc, map[string]any{
+		"b": 1,
+		"a": []any{"x", "z"},
+		"d": "new",
+	}))
+
+	out, err := yaml.Marshal(&doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "# graph\nb: 1\na: [x, z] # list\nd: new\n", string(out))
+}
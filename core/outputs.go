@@ -15,6 +15,7 @@ type SetOutputValueOpts struct {
 type HasOutputsInterface interface {
 	OutputDefsClone() map[OutputId]OutputDefinition
 	OutputDefByPortId(outputId string) (OutputDefinition, *IndexPortInfo, bool)
+	GetOutputIndexPorts() map[string]IndexPortInfo
 	SetOutputDefs(outputs map[OutputId]OutputDefinition, opts SetDefsOpts)
 
 	OutputValueById(c *ExecutionState, outputId OutputId) (value any, err error)
@@ -32,6 +33,10 @@ type Outputs struct {
 	owner                   NodeBaseInterface
 }
 
+func (n *Outputs) GetOutputIndexPorts() map[string]IndexPortInfo {
+	return n.outputIndexPorts
+}
+
 func (n *Outputs) OutputDefByPortId(outputId string) (OutputDefinition, *IndexPortInfo, bool) {
 	indexPort, ok := n.outputIndexPorts[outputId]
 	if !ok {
@@ -151,14 +156,20 @@ func (n *Outputs) SetOutputValue(ec *ExecutionState, outputId OutputId, value an
 		}
 	}
 
+	if ec.Events != nil {
+		ec.Events.outputSet(ec, n.owner, outputId, outputDef.Type)
+	}
+
 	// If the output is not connected, there's no need to keep the value. It can be discarded, unless
 	// for debug sessions where we always keep the output value, as it will be transmitted to the client for inspection
 	connectionCounter := n.outputConnectionCounter[outputId]
 	if connectionCounter == 0 && !ec.IsDebugSession {
-		// TODO: (Seb) If the value is a stream, we should close it here
+		closeStream(n.owner.GetId(), string(outputId), value, false)
 		return nil
 	}
 
+	value = ec.trackStream(n.owner.GetId(), string(outputId), value)
+	ec.closeReplacedStream(n.owner, string(outputId), value)
 	ec.CacheDataOutput(n.owner.GetCacheId(), string(outputId), value, Permanent)
 	return nil
 }
@@ -0,0 +1,215 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// PlanStep is a single node in the execution plan of a graph.
+type PlanStep struct {
+	Node NodeBaseInterface
+	// The execution input the node is entered through.
+	Port InputId
+	// The output of the previous node that leads to this node,
+	// only set if the previous node has more than one connected output.
+	Branch OutputId
+	// Number of branches the step is nested in.
+	Depth int
+	// True if the node has already been planned earlier with the same input.
+	Repeated bool
+}
+
+// PlanGraph walks the execution connections of a graph, starting at its entry node,
+// and returns the nodes in the order they would be executed. No node is executed.
+//
+// If a node has more than one connected execution output, like branches or loops,
+// each output is planned as its own branch. Group nodes are followed into their inner graph.
+func PlanGraph(ag *ActionGraph) ([]PlanStep, error) {
+	entry, ok := ag.FindNode(ag.Entry)
+	if !ok {
+		return nil, CreateErr(nil, nil, "entry '%s' not found", ag.Entry)
+	}
+
+	entryNode, ok := entry.(NodeBaseAndExecutionInterface)
+	if !ok {
+		return nil, CreateErr(nil, nil, "entry '%s' is not an execution node", ag.Entry)
+	}
+
+	p := &planner{
+		planned: map[string]bool{},
+	}
+
+	p.steps = append(p.steps, PlanStep{Node: entryNode})
+	p.planOutputs(entryNode, "", 0)
+
+	return p.steps, nil
+}
+
+type planner struct {
+	steps   []PlanStep
+	planned map[string]bool
+}
+
+func (p *planner) planNode(node NodeBaseAndExecutionInterface, port InputId, branch OutputId, depth int) {
+	nodeType := node.GetNodeTypeId()
+
+	// group inputs and outputs only forward the execution to the port with the same name
+	if strings.HasPrefix(nodeType, "core/group-inputs@") || strings.HasPrefix(nodeType, "core/group-outputs@") {
+		p.planForward(node, port, branch, depth)
+		return
+	}
+
+	if isGroupNode(node) && !isGroupEntry(node, port) {
+		// leaving the group continues on the outer level
+		p.planForward(node, port, branch, depth)
+		return
+	}
+
+	key := node.GetFullPath() + ":" + string(port)
+	if p.planned[key] {
+		p.steps = append(p.steps, PlanStep{Node: node, Port: port, Branch: branch, Depth: depth, Repeated: true})
+		return
+	}
+	p.planned[key] = true
+
+	p.steps = append(p.steps, PlanStep{Node: node, Port: port, Branch: branch, Depth: depth})
+
+	if isGroupNode(node) {
+		p.planForward(node, port, "", depth)
+		return
+	}
+
+	p.planOutputs(node, port, depth)
+}
+
+func (p *planner) planForward(node NodeBaseAndExecutionInterface, port InputId, branch OutputId, depth int) {
+	target, ok := node.GetExecutionTarget(OutputId(port))
+	if !ok || target.DstNode == nil {
+		return
+	}
+	p.planNode(target.DstNode, target.Port, branch, depth)
+}
+
+func (p *planner) planOutputs(node NodeBaseAndExecutionInterface, port InputId, depth int) {
+	outputs := connectedExecutionOutputs(node)
+
+	if len(outputs) == 1 {
+		target, _ := node.GetExecutionTarget(outputs[0])
+		p.planNode(target.DstNode, target.Port, "", depth)
+		return
+	}
+
+	for _, outputId := range outputs {
+		target, _ := node.GetExecutionTarget(outputId)
+		p.planNode(target.DstNode, target.Port, outputId, depth+1)
+	}
+}
+
+// connectedExecutionOutputs returns the connected execution outputs
+// of a node in the order of their port definitions.
+func connectedExecutionOutputs(node NodeBaseAndExecutionInterface) []OutputId {
+	outputNode, hasOutputs := node.(HasOutputsInterface)
+
+	type sortedOutput struct {
+		id         OutputId
+		index      int
+		arrayIndex int
+	}
+
+	var outputs []sortedOutput
+	for _, outputId := range connectedOutputIds(node) {
+		so := sortedOutput{id: outputId}
+		if hasOutputs {
+			def, indexPort, ok := outputNode.OutputDefByPortId(string(outputId))
+			if ok {
+				so.index = def.Index
+			}
+			if indexPort != nil {
+				so.arrayIndex = indexPort.Index
+			}
+		}
+		outputs = append(outputs, so)
+	}
+
+	slices.SortFunc(outputs, func(a, b sortedOutput) int {
+		if a.index != b.index {
+			return a.index - b.index
+		}
+		if a.arrayIndex != b.arrayIndex {
+			return a.arrayIndex - b.arrayIndex
+		}
+		return strings.Compare(string(a.id), string(b.id))
+	})
+
+	ids := make([]OutputId, 0, len(outputs))
+	for _, o := range outputs {
+		ids = append(ids, o.id)
+	}
+	return ids
+}
+
+func connectedOutputIds(node NodeBaseAndExecutionInterface) []OutputId {
+	var ids []OutputId
+	for outputId, target := range node.GetExecutions() {
+		if target.DstNode != nil {
+			ids = append(ids, outputId)
+		}
+	}
+	return ids
+}
+
+// WritePlan prints the execution plan of a graph in a human readable form.
+// Nodes are indented by the branches and groups they are nested in.
+func WritePlan(w io.Writer, graphName string, steps []PlanStep) {
+	fmt.Fprintf(w, "Execution plan for '%s':\n", graphName)
+
+	for _, step := range steps {
+		level := step.Depth + strings.Count(step.Node.GetFullPath(), "/")
+		indent := strings.Repeat("  ", level+1)
+		if step.Branch != "" {
+			fmt.Fprintf(w, "%s[%s]\n", strings.Repeat("  ", level), step.Branch)
+		}
+
+		line := fmt.Sprintf("%s%s (%s)", indent, step.Node.GetFullPath(), step.Node.GetNodeTypeId())
+		if step.Port != "" && step.Port != "exec" {
+			line += fmt.Sprintf(" via '%s'", step.Port)
+		}
+		if step.Repeated {
+			line += ", see above"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// PlanGraphFromFile loads a graph file and prints its execution plan to `w`.
+func PlanGraphFromFile(graphFile string, w io.Writer) error {
+	graphContent, err := os.ReadFile(graphFile)
+	if err != nil {
+		return CreateErr(nil, err, "failed loading graph")
+	}
+
+	graphYaml := make(map[string]any)
+	err = yaml.Unmarshal(graphContent, &graphYaml)
+	if err != nil {
+		return CreateErr(nil, err, "failed to load yaml")
+	}
+
+	ag, errs := LoadGraph(graphYaml, nil, "", GraphLoadContext{Dir: filepath.Dir(graphFile)}, false)
+	if len(errs) > 0 {
+		return CreateErr(nil, errs[0], "failed to load graph")
+	}
+
+	steps, err := PlanGraph(&ag)
+	if err != nil {
+		return err
+	}
+
+	WritePlan(w, graphFile, steps)
+	return nil
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+// ExecutionPolicy holds the per-node `timeout`, `retries` and `retry_backoff`
+// settings of a node in the graph file.
+//
+//   - id: fetch-data
+//     type: core/http@v1
+//     timeout: 30s
+//     retries: 3
+//     retry_backoff: 2s
+//
+// The policy covers the node's own work only, which is everything up to the point
+// where the node hands over to one of its execution outputs. Nodes connected
+// downstream are neither subject to the deadline nor re-run if they fail.
+// Since nodes like loops hand over before they finished their own work, only
+// nodes that execute one of their execution outputs exactly once can have a policy.
+type ExecutionPolicy struct {
+	// Maximum duration of a single attempt. Zero means no timeout.
+	Timeout time.Duration
+	// Number of additional attempts after the first one failed.
+	Retries int
+	// Delay before the first retry. Doubles with every subsequent retry.
+	RetryBackoff time.Duration
+}
+
+func (p ExecutionPolicy) IsZero() bool {
+	return p.Timeout == 0 && p.Retries == 0
+}
+
+// nodeAttempt tracks a single attempt of a node that runs with an execution policy.
+type nodeAttempt struct {
+	node      NodeBaseInterface
+	parent    *nodeAttempt
+	parentCtx context.Context
+	ctx       context.Context
+	timeout   time.Duration
+	retryable bool
+	handedOff bool
+}
+
+// errRetryNode is returned to the node if it routes an error to its error
+// output while there are attempts left. The node returns it to the executor
+// which then starts the next attempt.
+type errRetryNode struct {
+	cause error
+}
+
+func (e *errRetryNode) Error() string {
+	return e.cause.Error()
+}
+
+func (e *errRetryNode) Unwrap() error {
+	return e.cause
+}
+
+// LoadExecutionPolicy reads the node-level policy settings from a node definition.
+func LoadExecutionPolicy(node NodeBaseInterface, nodeI map[string]any) (ExecutionPolicy, error) {
+	var (
+		p   ExecutionPolicy
+		err error
+	)
+
+	if v, ok := nodeI["timeout"]; ok {
+		p.Timeout, err = parsePolicyDuration("timeout", v)
+		if err != nil {
+			return ExecutionPolicy{}, err
+		}
+	}
+
+	if v, ok := nodeI["retries"]; ok {
+		retries, ok := v.(int)
+		if !ok || retries < 0 {
+			return ExecutionPolicy{}, CreateErr(nil, nil, "'retries' must be a non-negative integer, got '%v'", v)
+		}
+		p.Retries = retries
+	}
+
+	if v, ok := nodeI["retry_backoff"]; ok {
+		p.RetryBackoff, err = parsePolicyDuration("retry_backoff", v)
+		if err != nil {
+			return ExecutionPolicy{}, err
+		}
+	}
+
+	if !p.IsZero() && getResumeBehavior(node) != ResumeSkip {
+		return ExecutionPolicy{}, CreateErr(nil, nil, "nodes of type '%s' can't have a timeout or retries", node.GetNodeTypeId()).
+			SetHint("the policy ends when a node hands over to an execution output, so only nodes that execute one of them exactly once can have one")
+	}
+	return p, nil
+}
+
+// parsePolicyDuration accepts Go duration strings like '1m30s' or
+// a plain number which is interpreted as seconds.
+func parsePolicyDuration(name string, v any) (time.Duration, error) {
+	var d time.Duration
+	switch t := v.(type) {
+	case string:
+		var err error
+		d, err = time.ParseDuration(t)
+		if err != nil {
+			return 0, CreateErr(nil, err, "'%s' is not a valid duration", name).
+				SetHint("use a duration like '30s', '1m30s' or a number of seconds")
+		}
+	case int:
+		d = time.Duration(t) * time.Second
+	case float64:
+		d = time.Duration(t * float64(time.Second))
+	default:
+		return 0, CreateErr(nil, nil, "'%s' must be a duration, got '%v'", name, v)
+	}
+
+	if d < 0 {
+		return 0, CreateErr(nil, nil, "'%s' must not be negative", name)
+	}
+	return d, nil
+}
+
+// executeWithPolicy runs the node's ExecuteImpl, bound to the node's timeout
+// and re-runs it as long as it fails and retries are left.
+func executeWithPolicy(ec *ExecutionState, node NodeBaseAndExecutionInterface, port InputId, prevErr error, policy ExecutionPolicy) error {
+	backoff := policy.RetryBackoff
+
+	for attemptIndex := 0; ; attemptIndex++ {
+		attempt := &nodeAttempt{
+			node:      node,
+			parent:    ec.attempt,
+			parentCtx: ec.Ctx,
+			timeout:   policy.Timeout,
+			retryable: attemptIndex < policy.Retries,
+		}
+
+		var cancel context.CancelFunc
+		if policy.Timeout > 0 {
+			attempt.ctx, cancel = context.WithTimeout(ec.Ctx, policy.Timeout)
+		} else {
+			attempt.ctx, cancel = context.WithCancel(ec.Ctx)
+		}
+
+		ec.Ctx = attempt.ctx
+		ec.attempt = attempt
+
+		err := node.ExecuteImpl(ec, port, prevErr)
+
+		// If the node never handed over, its attempt scope is still active.
+		handedOff := attempt.handedOff
+		if !handedOff {
+			attempt.leave(ec)
+		}
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		var retryErr *errRetryNode
+		isRetrySignal := errors.As(err, &retryErr)
+
+		if handedOff && !isRetrySignal {
+			// The error comes from a node further downstream
+			return err
+		}
+
+		if isRetrySignal {
+			err = retryErr.cause
+		} else {
+			err = attempt.wrapTimeout(ec, err)
+		}
+
+		if !attempt.retryable || ec.IsCancelled() {
+			return err
+		}
+
+		utils.LogErr.Warnf("node '%s' (%s) failed, retrying (%d/%d): %s\n",
+			node.GetName(),
+			node.GetId(),
+			attemptIndex+1,
+			policy.Retries,
+			err,
+		)
+
+		if backoff > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ec.Ctx.Done():
+				return err
+			}
+			backoff *= 2
+		}
+
+		// Inputs are fetched again by the next attempt
+		ec.EmptyDataOutputCache()
+	}
+}
+
+// leave restores the execution context that was active before the attempt.
+func (a *nodeAttempt) leave(ec *ExecutionState) {
+	ec.Ctx = a.parentCtx
+	ec.attempt = a.parent
+}
+
+func (a *nodeAttempt) wrapTimeout(ec *ExecutionState, err error) error {
+	if a.timeout > 0 && errors.Is(a.ctx.Err(), context.DeadlineExceeded) {
+		return CreateErr(ec, err, "node '%s' (%s) timed out after %s", a.node.GetName(), a.node.GetId(), a.timeout).
+			SetHint("increase the 'timeout' of node '%s' or make the node finish faster", a.node.GetFullPath())
+	}
+	return err
+}
+
+// handOver is called when the node of the active attempt fires one of its execution outputs.
+// An error routed to the error output is turned into a retry signal as long as attempts are left.
+func (a *nodeAttempt) handOver(ec *ExecutionState, err error) error {
+	if err != nil {
+		if a.retryable {
+			return &errRetryNode{cause: err}
+		}
+		err = a.wrapTimeout(ec, err)
+	}
+	a.handedOff = true
+	a.leave(ec)
+	return err
+}
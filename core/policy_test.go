@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadExecutionPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     map[string]any
+		expected ExecutionPolicy
+		wantErr  bool
+	}{
+		{
+			name:     "no policy",
+			node:     map[string]any{"id": "a"},
+			expected: ExecutionPolicy{},
+		},
+		{
+			name: "duration strings",
+			node: map[string]any{"timeout": "1m30s", "retries": 2, "retry_backoff": "500ms"},
+			expected: ExecutionPolicy{
+				Timeout:      90 * time.Second,
+				Retries:      2,
+				RetryBackoff: 500 * time.Millisecond,
+			},
+		},
+		{
+			name:     "seconds as numbers",
+			node:     map[string]any{"timeout": 10, "retry_backoff": 0.5},
+			expected: ExecutionPolicy{Timeout: 10 * time.Second, RetryBackoff: 500 * time.Millisecond},
+		},
+		{
+			name:    "invalid duration",
+			node:    map[string]any{"timeout": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "negative retries",
+			node:    map[string]any{"retries": -1},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			node:    map[string]any{"timeout": "-5s"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := LoadExecutionPolicy(nil, tt.node)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+}
+
+type repeatingTestNode struct {
+	NodeBaseComponent
+}
+
+func (n *repeatingTestNode) GetResumeBehavior() ResumeBehavior {
+	return ResumeRestart
+}
+
+func TestLoadExecutionPolicyOfRepeatingNode(t *testing.T) {
+	n := &repeatingTestNode{}
+
+	// the policy would end with the first iteration
+	_, err := LoadExecutionPolicy(n, map[string]any{"retries": 1})
+	assert.Error(t, err)
+
+	p, err := LoadExecutionPolicy(n, map[string]any{"retry_backoff": "1s"})
+	assert.NoError(t, err)
+	assert.True(t, p.IsZero())
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+// PostStepFunc runs a post step. `runErr` is the error the graph finished with, if any.
+type PostStepFunc func(c *ExecutionState, runErr error) error
+
+type postStep struct {
+	name  string
+	node  NodeBaseInterface
+	state *ExecutionState
+	fn    PostStepFunc
+}
+
+// PostSteps are the steps that run after the graph finished, like the `post` of a
+// GitHub Action that cleans up or saves a cache. They are shared by all execution
+// states of a run.
+type PostSteps struct {
+	lock  sync.Mutex
+	steps []postStep
+}
+
+// AddPostStep queues a step of the node `n` that runs after the graph finished, whether
+// it succeeded or failed. The step runs in a copy of the execution state `c`.
+func (c *ExecutionState) AddPostStep(n NodeBaseInterface, name string, fn PostStepFunc) {
+	c.PostSteps.lock.Lock()
+	defer c.PostSteps.lock.Unlock()
+
+	c.PostSteps.steps = append(c.PostSteps.steps, postStep{
+		name:  name,
+		node:  n,
+		state: c,
+		fn:    fn,
+	})
+}
+
+// run runs the queued post steps in the reverse order they were added. A failed
+// post step doesn't stop the others. The error of the first failed one is returned.
+func (p *PostSteps) run(runErr error) error {
+	p.lock.Lock()
+	steps := p.steps
+	p.steps = nil
+	p.lock.Unlock()
+
+	var firstErr error
+	for _, s := range slices.Backward(steps) {
+		pc := s.state.PushNewExecutionState(s.node)
+
+		// post steps clean up after their node, so they also run if the run was cancelled.
+		// The context of the pushed state is replaced, so it's released right away.
+		pc.CtxCancel()
+		pc.Ctx, pc.CtxCancel = context.WithCancel(context.WithoutCancel(s.state.Ctx))
+
+		err := s.fn(pc, runErr)
+		pc.Cancel()
+		if err != nil {
+			utils.LogErr.Errorf("post step '%s' failed\n", s.name)
+			if firstErr == nil {
+				firstErr = CreateErr(nil, err, "post step '%s' failed", s.name)
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,305 @@
+package core
+
+import (
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var SchemaKinds = []string{"act", "node"}
+
+// ActSchema returns the JSON Schema of the `.act` graph file format. The inputs
+// of nodes are checked against the input definitions of their node type, so the
+// schema depends on the node types in the registry.
+func ActSchema() map[string]any {
+	g := &schemaGenerator{defs: map[string]any{}}
+
+	agType := reflect.TypeFor[ActionGraph]()
+	inputsField, _ := agType.FieldByName("Inputs")
+	outputsField, _ := agType.FieldByName("Outputs")
+	semaphoresField, _ := agType.FieldByName("Semaphores")
+
+	semaphores := g.typeSchema(semaphoresField.Type)
+	semaphores["additionalProperties"].(map[string]any)["minimum"] = 1
+
+	g.defs["expression"] = map[string]any{
+		"type":    "string",
+		"pattern": `\$\{\{`,
+	}
+	g.defs["duration"] = map[string]any{
+		"description": "a duration like '30s' or '1m30s', or a number of seconds",
+		"type":        []string{"string", "number"},
+	}
+	g.defs["port"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"node": map[string]any{"type": "string"},
+			"port": map[string]any{"type": "string"},
+		},
+		"required": []string{"node", "port"},
+	}
+	g.defs["connection"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"src": map[string]any{"$ref": "#/$defs/port"},
+			"dst": map[string]any{"$ref": "#/$defs/port"},
+		},
+		"required": []string{"src", "dst"},
+	}
+
+	nodeTypes := slices.Sorted(maps.Keys(registries))
+
+	// the inputs of a node are checked if its type is known
+	var nodeInputs []any
+	for _, nodeType := range nodeTypes {
+		key := "inputs:" + nodeType
+		g.defs[key] = nodeInputsSchema(registries[nodeType])
+		nodeInputs = append(nodeInputs, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"type": map[string]any{"const": nodeType}},
+				"required":   []string{"type"},
+			},
+			"then": map[string]any{
+				"properties": map[string]any{"inputs": map[string]any{"$ref": schemaDefRef(key)}},
+			},
+		})
+	}
+
+	g.defs["node"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{"type": "string"},
+			"type": map[string]any{
+				"anyOf": []any{
+					map[string]any{"enum": nodeTypes},
+					map[string]any{"type": "string", "pattern": `^github\.com/`},
+					map[string]any{
+						"description": "a node type of the first versions without the 'core/' prefix, see 'actrun migrate'",
+						"type":        "string",
+						"pattern":     legacyNodeTypeRegex.String(),
+						"deprecated":  true,
+					},
+				},
+			},
+			"position": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"x": map[string]any{"type": "number"},
+					"y": map[string]any{"type": "number"},
+				},
+			},
+			"inputs":        map[string]any{"type": "object"},
+			"outputs":       map[string]any{"type": "object"},
+			"graph":         map[string]any{"$ref": "#"},
+			"ref":           map[string]any{"type": "string"},
+			"isolated":      map[string]any{"type": "boolean"},
+			"cache":         map[string]any{"type": "boolean"},
+			"timeout":       map[string]any{"$ref": "#/$defs/duration"},
+			"retries":       map[string]any{"type": "integer", "minimum": 0},
+			"retry_backoff": map[string]any{"$ref": "#/$defs/duration"},
+		},
+		"required": []string{"id", "type"},
+		"allOf":    nodeInputs,
+	}
+
+	return map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   "Action graph",
+		"type":    "object",
+		"properties": map[string]any{
+			"editor":     map[string]any{"type": "object"},
+			"entry":      map[string]any{"type": "string"},
+			"type":       map[string]any{"type": "string"},
+			"inputs":     g.typeSchema(inputsField.Type),
+			"outputs":    g.typeSchema(outputsField.Type),
+			"semaphores": semaphores,
+			"nodes": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/node"},
+			},
+			"connections": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/connection"},
+			},
+			"executions": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/connection"},
+			},
+		},
+		"required": []string{"entry", "nodes", "connections", "executions"},
+		"$defs":    g.defs,
+	}
+}
+
+// NodeSchema returns the JSON Schema of the node type definitions in the `.yml` files of nodes.
+func NodeSchema() map[string]any {
+	g := &schemaGenerator{defs: map[string]any{}}
+	root := g.typeSchema(reflect.TypeFor[NodeTypeDefinitionFull]())
+
+	// see `NodeTypeDefinitionFull.IsValid`
+	def := g.defs["NodeTypeDefinitionFull"].(map[string]any)
+	def["required"] = []string{"id", "name", "version"}
+	def["properties"].(map[string]any)["version"] = map[string]any{"type": []string{"string", "integer"}}
+
+	option := g.defs["InputOption"].(map[string]any)
+	option["required"] = []string{"name", "value"}
+
+	return map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   "Node type definition",
+		"$ref":    root["$ref"],
+		"$defs":   g.defs,
+	}
+}
+
+// nodeInputsSchema returns the schema of the input values of a node type. Array
+// inputs can also be given by their index ports, like `values[0]`.
+func nodeInputsSchema(nodeDef NodeTypeDefinitionFull) map[string]any {
+	properties := map[string]any{}
+	patternProperties := map[string]any{}
+
+	for inputId, def := range nodeDef.Inputs {
+		// execution inputs have no value
+		elem := map[string]any{"type": "null"}
+		if !def.Exec {
+			elem = inputValueSchema(def.Type, def.Options)
+		}
+		if def.Array {
+			patternProperties["^"+regexp.QuoteMeta(string(inputId))+`\[[0-9]+\]$`] = elem
+			elem = map[string]any{"type": "array", "items": elem}
+		}
+		if def.Name != "" {
+			elem["title"] = def.Name
+		}
+		if def.Desc != "" {
+			elem["description"] = def.Desc
+		}
+		if def.Default != nil {
+			elem["default"] = def.Default
+		}
+		properties[string(inputId)] = elem
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(patternProperties) > 0 {
+		schema["patternProperties"] = patternProperties
+	}
+	return schema
+}
+
+// inputValueSchema returns the schema of a value of an input type. Strings are
+// evaluated as expressions for every type, so they are always accepted. A null
+// value is the same as no value, which is used by connected index ports.
+func inputValueSchema(typ string, options []InputOption) map[string]any {
+	expression := map[string]any{"$ref": "#/$defs/expression"}
+	null := map[string]any{"type": "null"}
+
+	switch typ {
+	case "string", "secret":
+		return map[string]any{"type": []string{"string", "null"}}
+	case "number":
+		return map[string]any{"anyOf": []any{map[string]any{"type": "number"}, expression, null}}
+	case "bool":
+		return map[string]any{"anyOf": []any{map[string]any{"type": "boolean"}, expression, null}}
+	case "option":
+		values := []string{}
+		for _, o := range options {
+			values = append(values, o.Value)
+		}
+		return map[string]any{"anyOf": []any{
+			map[string]any{"enum": values},
+			// options can also be selected by their index
+			map[string]any{"type": "integer", "minimum": 0, "maximum": max(len(values)-1, 0)},
+			expression,
+			null,
+		}}
+	}
+
+	if elemType, ok := strings.CutPrefix(typ, "[]"); ok {
+		return map[string]any{"anyOf": []any{
+			map[string]any{"type": "array", "items": inputValueSchema(elemType, nil)},
+			expression,
+			null,
+		}}
+	}
+	return map[string]any{}
+}
+
+// schemaGenerator generates JSON Schemas from Go types using their yaml field names.
+// Named struct types are added to `defs` and referenced where they are used.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.typeSchema(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			// reserve the name first for types that reference themselves
+			g.defs[name] = map[string]any{}
+
+			properties := map[string]any{}
+			g.addProperties(t, properties)
+			g.defs[name] = map[string]any{
+				"type":       "object",
+				"properties": properties,
+			}
+		}
+		return map[string]any{"$ref": schemaDefRef(name)}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": g.typeSchema(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+func (g *schemaGenerator) addProperties(t reflect.Type, properties map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		} else if strings.Contains(opts, "inline") {
+			g.addProperties(f.Type, properties)
+			continue
+		} else if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		properties[name] = g.typeSchema(f.Type)
+	}
+}
+
+// schemaDefRef returns the reference to a definition in `$defs`, escaped as a JSON pointer.
+func schemaDefRef(name string) string {
+	return "#/$defs/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
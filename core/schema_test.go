@@ -0,0 +1,66 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeInputsSchema(t *testing.T) {
+	schema := nodeInputsSchema(NodeTypeDefinitionFull{
+		Inputs: map[InputId]InputDefinition{
+			"exec": {PortDefinition: PortDefinition{Exec: true}},
+			"color": {
+				PortDefinition: PortDefinition{Name: "Color", Type: "option"},
+				Default:        "red",
+				Options:        []InputOption{{Name: "Red", Value: "red"}, {Name: "Blue", Value: "blue"}},
+			},
+			"values": {PortDefinition: PortDefinition{Type: "string", Array: true}},
+		},
+	})
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "null"}, properties["exec"])
+
+	color := properties["color"].(map[string]any)
+	assert.Equal(t, "Color", color["title"])
+	assert.Equal(t, "red", color["default"])
+	assert.Contains(t, color["anyOf"], map[string]any{"enum": []string{"red", "blue"}})
+
+	values := properties["values"].(map[string]any)
+	assert.Equal(t, "array", values["type"])
+	assert.Contains(t, schema["patternProperties"], `^values\[[0-9]+\]$`)
+}
+
+func TestSchemaGenerator(t *testing.T) {
+	type outer struct {
+		Inline  PortDefinition `yaml:",inline"`
+		Count   int            `yaml:"count,omitempty"`
+		Values  map[string]any `yaml:"values"`
+		Ignored func()         `yaml:"-"`
+	}
+
+	g := &schemaGenerator{defs: map[string]any{}}
+	assert.Equal(t, map[string]any{"$ref": "#/$defs/outer"}, g.typeSchema(reflect.TypeFor[outer]()))
+
+	properties := g.defs["outer"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["count"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, properties["exec"])
+	assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{}}, properties["values"])
+	assert.NotContains(t, properties, "ignored")
+}
+
+func TestSchemaDefRef(t *testing.T) {
+	assert.Equal(t, "#/$defs/inputs:core~1print@v1", schemaDefRef("inputs:core/print@v1"))
+}
+
+func TestActSchemaLegacyNodeTypes(t *testing.T) {
+	nodeType := ActSchema()["$defs"].(map[string]any)["node"].(map[string]any)["properties"].(map[string]any)["type"].(map[string]any)
+	assert.Contains(t, nodeType["anyOf"], map[string]any{
+		"description": "a node type of the first versions without the 'core/' prefix, see 'actrun migrate'",
+		"type":        "string",
+		"pattern":     `^[\w-]+@v1$`,
+		"deprecated":  true,
+	})
+}
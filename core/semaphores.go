@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// Semaphores are the named semaphores of a run. They are defined at the top level
+// of the graph file and limit how many concurrent branches can use a resource,
+// e.g. `gpu: 1` or `license-server: 4`.
+type Semaphores struct {
+	capacities map[string]int64
+	weighted   map[string]*semaphore.Weighted
+}
+
+func NewSemaphores(capacities map[string]int64) *Semaphores {
+	s := &Semaphores{
+		capacities: capacities,
+		weighted:   make(map[string]*semaphore.Weighted, len(capacities)),
+	}
+	for name, capacity := range capacities {
+		s.weighted[name] = semaphore.NewWeighted(capacity)
+	}
+	return s
+}
+
+// LoadGraphSemaphores loads the semaphore definitions of a graph, a map of
+// names to their capacity.
+func LoadGraphSemaphores(graphYaml map[string]any) (map[string]int64, error) {
+	semaphoresAny, ok := graphYaml["semaphores"]
+	if !ok || semaphoresAny == nil {
+		return nil, nil
+	}
+
+	semaphores, ok := semaphoresAny.(map[string]any)
+	if !ok {
+		return nil, CreateErr(nil, nil, "semaphores must be a map of names to capacities")
+	}
+
+	capacities := make(map[string]int64, len(semaphores))
+	for name, v := range semaphores {
+		capacity, ok := v.(int)
+		if !ok || capacity < 1 {
+			return nil, CreateErr(nil, nil, "capacity of semaphore '%s' must be a positive integer, got '%v'", name, v).
+				SetHint("use the number of branches that can hold the semaphore at the same time, e.g. '%s: 1'", name)
+		}
+		capacities[name] = int64(capacity)
+	}
+
+	return capacities, nil
+}
+
+// AcquireSemaphore acquires `count` units of the semaphore `name`. It blocks until
+// they are available or the execution state is cancelled. The returned function
+// releases them again.
+func (c *ExecutionState) AcquireSemaphore(name string, count int64) (func(), error) {
+	var capacities map[string]int64
+	if c.Semaphores != nil {
+		capacities = c.Semaphores.capacities
+	}
+
+	sem, ok := c.Semaphores.get(name)
+	if !ok {
+		names := slices.Sorted(maps.Keys(capacities))
+		hint := "define it in the 'semaphores' section of the graph"
+		if len(names) > 0 {
+			hint = fmt.Sprintf("%s, defined semaphores are: %s", hint, strings.Join(names, ", "))
+		}
+		return nil, CreateErr(c, nil, "semaphore '%s' is not defined", name).SetHint("%s", hint)
+	}
+
+	if count < 1 || count > capacities[name] {
+		return nil, CreateErr(c, nil, "can't acquire %d of semaphore '%s' with a capacity of %d", count, name, capacities[name])
+	}
+
+	err := sem.Acquire(c.Ctx, count)
+	if err != nil {
+		return nil, CreateErr(c, err, "cancelled while waiting for semaphore '%s'", name)
+	}
+
+	return func() {
+		sem.Release(count)
+	}, nil
+}
+
+func (s *Semaphores) get(name string) (*semaphore.Weighted, bool) {
+	if s == nil {
+		return nil, false
+	}
+	sem, ok := s.weighted[name]
+	return sem, ok
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireSemaphore(t *testing.T) {
+	c := &ExecutionState{
+		Ctx:        context.Background(),
+		Semaphores: NewSemaphores(map[string]int64{"license-server": 2}),
+	}
+
+	// three branches try to acquire the semaphore, the first two hold it until
+	// it's checked that the third one has to wait
+	var wg sync.WaitGroup
+	acquired := make(chan struct{}, 3)
+	hold := make(chan struct{})
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := c.AcquireSemaphore("license-server", 1)
+			if !assert.NoError(t, err) {
+				return
+			}
+			acquired <- struct{}{}
+			<-hold
+			release()
+		}()
+	}
+
+	for range 2 {
+		select {
+		case <-acquired:
+		case <-time.After(5 * time.Second):
+			t.Fatal("semaphore was not acquired")
+		}
+	}
+	select {
+	case <-acquired:
+		t.Fatal("semaphore was acquired more often than its capacity")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(hold)
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("semaphore was not acquired after it was released")
+	}
+	wg.Wait()
+
+	_, err := c.AcquireSemaphore("gpu", 1)
+	assert.ErrorContains(t, err, "semaphore 'gpu' is not defined")
+
+	_, err = c.AcquireSemaphore("license-server", 3)
+	assert.ErrorContains(t, err, "capacity of 2")
+
+	release, err := c.AcquireSemaphore("license-server", 2)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Ctx = ctx
+	_, err = c.AcquireSemaphore("license-server", 1)
+	assert.ErrorContains(t, err, "cancelled while waiting")
+	release()
+}
+
+func TestLoadGraphSemaphores(t *testing.T) {
+	capacities, err := LoadGraphSemaphores(map[string]any{
+		"semaphores": map[string]any{"gpu": 1, "license-server": 4},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"gpu": 1, "license-server": 4}, capacities)
+
+	for _, capacity := range []any{0, -1, "one", 1.5} {
+		_, err = LoadGraphSemaphores(map[string]any{
+			"semaphores": map[string]any{"gpu": capacity},
+		})
+		assert.Error(t, err, "capacity %v", capacity)
+	}
+}
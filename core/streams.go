@@ -0,0 +1,186 @@
+package core
+
+import (
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+// trackedStream wraps the reader of a stream that a node sets as output value.
+// The executor closes it as soon as it's no longer needed:
+//
+//   - when it's set to an output that isn't connected,
+//   - when the output is set again in the same execution state, eg. in the next
+//     iteration of a loop, since no node can read the previous value anymore,
+//   - when the run ends, for all streams that are still open. Nodes that are executed
+//     after the node returned, eg. after the loop it's in, can still read its streams.
+//
+// Closing is idempotent, so nodes that close the streams they consume themselves
+// are not affected. Nodes must not close the streams they produce when they return.
+type trackedStream struct {
+	io.Reader
+
+	nodeId   string
+	outputId string
+	open     *openStreams
+
+	read      atomic.Bool
+	closed    atomic.Bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (s *trackedStream) Read(p []byte) (int, error) {
+	s.read.Store(true)
+	return s.Reader.Read(p)
+}
+
+func (s *trackedStream) Close() error {
+	s.closed.Store(true)
+	s.closeOnce.Do(func() {
+		s.open.remove(s)
+		s.closeErr = utils.SafeCloseReader(s.Reader)
+	})
+	return s.closeErr
+}
+
+// openStreams are the tracked streams of a run that aren't closed yet. All execution states
+// of a run share them, so the streams of nested execution states are closed at its end too.
+type openStreams struct {
+	lock    sync.Mutex
+	streams map[*trackedStream]struct{}
+}
+
+func (o *openStreams) add(s *trackedStream) {
+	if o == nil {
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.streams == nil {
+		o.streams = map[*trackedStream]struct{}{}
+	}
+	o.streams[s] = struct{}{}
+}
+
+func (o *openStreams) remove(s *trackedStream) {
+	if o == nil {
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	delete(o.streams, s)
+}
+
+func (o *openStreams) list() []*trackedStream {
+	if o == nil {
+		return nil
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return slices.Collect(maps.Keys(o.streams))
+}
+
+// trackStream wraps the reader of a stream value, so its lifetime can be tracked.
+func (c *ExecutionState) trackStream(nodeId string, outputId string, value any) any {
+	dsf, ok := value.(DataStreamFactory)
+	if !ok || dsf.Reader == nil || isStdStream(dsf.Reader) {
+		return value
+	}
+
+	if _, tracked := dsf.Reader.(*trackedStream); !tracked {
+		s := &trackedStream{Reader: dsf.Reader, nodeId: nodeId, outputId: outputId, open: c.streams}
+		c.streams.add(s)
+		dsf.Reader = s
+	}
+	return dsf
+}
+
+// The standard streams of the process outlive the run and are never closed.
+func isStdStream(r io.Reader) bool {
+	return r == os.Stdin || r == os.Stdout || r == os.Stderr
+}
+
+// closeStream closes `value` if it's a stream. A stream that is closed even though it
+// was connected to another node but never read is reported in the debug log.
+func closeStream(nodeId string, outputId string, value any, connected bool) {
+	switch v := value.(type) {
+	case DataStreamFactory:
+		if v.Reader == nil || isStdStream(v.Reader) {
+			return
+		}
+		if s, ok := v.Reader.(*trackedStream); ok {
+			s.closeUnread(connected)
+			return
+		}
+		_ = utils.SafeCloseReader(v.Reader)
+	case *io.PipeReader:
+		_ = v.Close()
+	}
+}
+
+func (s *trackedStream) closeUnread(connected bool) {
+	if connected && !s.read.Load() && !s.closed.Load() {
+		utils.LogOut.Debugf("stream of output '%s' of node '%s' was never read\n", s.outputId, s.nodeId)
+	}
+	_ = s.Close()
+}
+
+// closeReplacedStream closes the stream that an output had in this execution state before
+// it's set to `value`, eg. by the previous iteration of a loop.
+func (c *ExecutionState) closeReplacedStream(node NodeBaseInterface, outputId string, value any) {
+	c.OutputCacheLock.RLock()
+	prev, ok := c.ExecutionOutputCache[node.GetCacheId()+":"+outputId]
+	c.OutputCacheLock.RUnlock()
+	if !ok || sameStream(prev, value) {
+		return
+	}
+	closeStream(node.GetId(), outputId, prev, true)
+}
+
+func sameStream(a any, b any) bool {
+	switch v := a.(type) {
+	case DataStreamFactory:
+		w, ok := b.(DataStreamFactory)
+		return ok && v.Reader == w.Reader
+	case *io.PipeReader:
+		return v == b
+	}
+	return false
+}
+
+// CloseStreams closes all streams of the run that are still open when it ends.
+func (c *ExecutionState) CloseStreams() {
+	for cacheId, value := range c.streamOutputs() {
+		// cache ids of output values are '<node-id>:<uuid>:<output-id>'
+		parts := strings.SplitN(cacheId, ":", 3)
+		if len(parts) == 3 {
+			closeStream(parts[0], parts[2], value, true)
+		}
+	}
+	for _, s := range c.streams.list() {
+		s.closeUnread(true)
+	}
+}
+
+// streamOutputs returns the output values of the execution nodes in this
+// execution state that are streams, by their cache id.
+func (c *ExecutionState) streamOutputs() map[string]any {
+	c.OutputCacheLock.RLock()
+	defer c.OutputCacheLock.RUnlock()
+
+	streams := map[string]any{}
+	for cacheId, value := range c.ExecutionOutputCache {
+		switch value.(type) {
+		case DataStreamFactory, *io.PipeReader:
+			streams[cacheId] = value
+		}
+	}
+	return streams
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closeCounter struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return nil
+}
+
+type streamTestNode struct {
+	NodeBaseComponent
+	Outputs
+}
+
+func TestStreamOutputsAreClosed(t *testing.T) {
+	c := &ExecutionState{
+		Ctx:                  context.Background(),
+		ContextStackLock:     &sync.RWMutex{},
+		OutputCacheLock:      &sync.RWMutex{},
+		DataOutputCache:      map[string]any{},
+		ExecutionOutputCache: map[string]any{},
+		streams:              &openStreams{},
+	}
+
+	n := &streamTestNode{}
+	n.SetId("read")
+	n.SetOwner(n)
+	n.SetOutputDefs(map[OutputId]OutputDefinition{
+		"connected":   {PortDefinition: PortDefinition{Type: "stream"}},
+		"unconnected": {PortDefinition: PortDefinition{Type: "stream"}},
+	}, SetDefsOpts{})
+	n.IncrementConnectionCounter("connected")
+
+	unconnected := &closeCounter{Reader: strings.NewReader("a")}
+	assert.NoError(t, n.SetOutputValue(c, "unconnected", DataStreamFactory{Reader: unconnected}, SetOutputValueOpts{}))
+	assert.Equal(t, 1, unconnected.closed, "unconnected streams are closed right away")
+
+	connected := &closeCounter{Reader: strings.NewReader("b")}
+	assert.NoError(t, n.SetOutputValue(c, "connected", DataStreamFactory{Reader: connected}, SetOutputValueOpts{}))
+	assert.Equal(t, 0, connected.closed)
+
+	// consumers close the stream themselves, which must not close it twice
+	value, ok := c.GetDataFromOutputCache(n.GetCacheId(), "connected", Permanent)
+	assert.True(t, ok)
+	dsf := value.(DataStreamFactory)
+	_, err := io.ReadAll(dsf.Reader)
+	assert.NoError(t, err)
+	assert.NoError(t, dsf.CloseStream())
+
+	// setting the output again, eg. in the next iteration of a loop, closes the previous stream
+	replaced := &closeCounter{Reader: strings.NewReader("c")}
+	assert.NoError(t, n.SetOutputValue(c, "connected", DataStreamFactory{Reader: replaced}, SetOutputValueOpts{}))
+	last := &closeCounter{Reader: strings.NewReader("d")}
+	assert.NoError(t, n.SetOutputValue(c, "connected", DataStreamFactory{Reader: last}, SetOutputValueOpts{}))
+	assert.Equal(t, 1, replaced.closed)
+
+	// nodes executed after the node returned can still read the last stream until the run ends
+	assert.Equal(t, 0, last.closed)
+	c.CloseStreams()
+	assert.Equal(t, 1, connected.closed)
+	assert.Equal(t, 1, last.closed)
+	assert.Empty(t, c.streams.list())
+}
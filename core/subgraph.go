@@ -0,0 +1,243 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/actionforge/actrun-cli/utils"
+	"go.yaml.in/yaml/v4"
+)
+
+// GraphRef describes the file a group node loaded its graph from via `ref`.
+type GraphRef struct {
+	// The reference as written in the graph file.
+	Ref string
+	// Absolute path of the loaded graph file. Relative references inside
+	// the loaded graph are resolved against its directory.
+	Path string
+}
+
+// GraphLoadContext is passed as `ctx` to the factories of the nodes of a graph that is loaded with `LoadGraph`.
+type GraphLoadContext struct {
+	// Cancels loading, eg. the git commands that fetch referenced graphs.
+	Ctx context.Context
+	// Directory of the graph file the nodes are defined in. Empty if the graph wasn't loaded from a file.
+	Dir string
+}
+
+// HasGraphRefInterface is implemented by group nodes. `GetGraphRef` returns nil
+// if the group's graph is defined inline.
+type HasGraphRefInterface interface {
+	GetGraphRef() *GraphRef
+}
+
+// LoadGraphRef loads the group graph referenced by `ref` for a group node inside `parent`.
+//
+// `ref` is either a path to an .act file or a git URL of the form
+// `https://host/owner/repo.git/path/to/graph.act@ref`. Relative paths are resolved
+// against the directory of the graph file that contains the reference (`lc.Dir`),
+// or against the working directory if the graph wasn't loaded from a file.
+func LoadGraphRef(ref string, parent NodeBaseInterface, lc GraphLoadContext) (map[string]any, *GraphRef, error) {
+	graphRef, err := resolveGraphRef(ref, lc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A graph that references itself, directly or through other graphs, would never finish loading.
+	chain := []string{graphRef.Ref}
+	for n := parent; n != nil; n = n.GetParent() {
+		refNode, ok := n.(HasGraphRefInterface)
+		if !ok || refNode.GetGraphRef() == nil {
+			continue
+		}
+		chain = append(chain, refNode.GetGraphRef().Ref)
+		if refNode.GetGraphRef().Path == graphRef.Path {
+			for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+				chain[i], chain[j] = chain[j], chain[i]
+			}
+			return nil, nil, CreateErr(nil, nil, "graph '%s' references itself: %s", graphRef.Ref, strings.Join(chain, " -> ")).
+				SetHint("remove the reference that closes the cycle")
+		}
+	}
+
+	content, err := os.ReadFile(graphRef.Path)
+	if err != nil {
+		return nil, nil, CreateErr(nil, err, "failed to read referenced graph '%s'", graphRef.Ref)
+	}
+
+	var graphYaml map[string]any
+	err = yaml.Unmarshal(content, &graphYaml)
+	if err != nil {
+		return nil, nil, CreateErr(nil, err, "failed to load referenced graph '%s'", graphRef.Ref)
+	}
+
+	graphType, _ := graphYaml["type"].(string)
+	if graphType != "group" {
+		return nil, nil, CreateErr(nil, nil, "referenced graph '%s' is not a group graph", graphRef.Ref).
+			SetHint("only graphs with 'type: group' and group input and output nodes can be referenced")
+	}
+
+	return graphYaml, graphRef, nil
+}
+
+func resolveGraphRef(ref string, lc GraphLoadContext) (*GraphRef, error) {
+	repoUrl, path, rev, isGit := parseGitGraphRef(ref)
+	if isGit {
+		ctx := lc.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		root, err := fetchGraphRepo(ctx, repoUrl, rev)
+		if err != nil {
+			return nil, err
+		}
+		return &GraphRef{
+			Ref:  ref,
+			Path: filepath.Join(root, filepath.FromSlash(path)),
+		}, nil
+	}
+
+	path = filepath.FromSlash(ref)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(lc.Dir, path)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, CreateErr(nil, err, "unable to resolve referenced graph '%s'", ref)
+	}
+
+	return &GraphRef{
+		Ref:  ref,
+		Path: path,
+	}, nil
+}
+
+// graphFileDir returns the directory of the graph file `graphName`, or an empty string if the graph
+// wasn't loaded from a file, like graphs of the web app. Then references are resolved against the working directory.
+func graphFileDir(graphName string) string {
+	fi, err := os.Stat(graphName)
+	if err != nil || fi.IsDir() {
+		return ""
+	}
+	return filepath.Dir(graphName)
+}
+
+// parseGitGraphRef splits a reference like `https://github.com/owner/repo.git/graphs/build.act@v1`
+// into the repository URL, the path of the graph in the repository and the git revision.
+func parseGitGraphRef(ref string) (repoUrl string, path string, rev string, ok bool) {
+	if !strings.Contains(ref, "://") && !strings.HasPrefix(ref, "git@") {
+		return "", "", "", false
+	}
+
+	repoUrl, path, ok = strings.Cut(ref, ".git/")
+	if !ok {
+		return "", "", "", false
+	}
+
+	path, rev, _ = strings.Cut(path, "@")
+	return repoUrl + ".git", path, rev, true
+}
+
+// fetchGraphRepo clones the repository into the cache directory, or updates an
+// already cached clone, and returns the path of the checkout. The git commands
+// are killed when `ctx` is cancelled.
+func fetchGraphRepo(ctx context.Context, repoUrl string, rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", CreateErr(nil, err, "unable to get user cache directory")
+	}
+
+	hash := sha256.Sum256([]byte(repoUrl))
+	repoRoot := filepath.Join(cacheDir, "actrun", "subgraphs", hex.EncodeToString(hash[:8]), rev)
+
+	_, err = os.Stat(repoRoot)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(repoRoot), 0755); err != nil {
+			return "", CreateErr(nil, err, "unable to create subgraph cache directory")
+		}
+
+		c := exec.CommandContext(ctx, "git", "clone", "--quiet", "--no-checkout", repoUrl, repoRoot)
+		c.Stderr = os.Stderr
+		err = c.Run()
+		if err != nil {
+			// a clone that was interrupted, eg. by a cancelled run, is not a valid cache
+			_ = os.RemoveAll(repoRoot)
+			return "", CreateErr(nil, err, "failed to clone '%s'", repoUrl)
+		}
+
+		c = exec.CommandContext(ctx, "git", "checkout", "--quiet", rev)
+		c.Stderr = os.Stderr
+		c.Dir = repoRoot
+		err = c.Run()
+		if err != nil {
+			// don't keep a clone that is not at the requested revision
+			_ = os.RemoveAll(repoRoot)
+			return "", CreateErr(nil, err, "failed to check out '%s' of '%s'", rev, repoUrl)
+		}
+	} else {
+		// A branch or tag may point to another commit by now, so it's fetched again.
+		// Only a full commit SHA always refers to the same graph.
+		target := rev
+		if !reCommitSha.MatchString(rev) {
+			c := exec.CommandContext(ctx, "git", "fetch", "--quiet", "--force", "origin", rev)
+			c.Stderr = os.Stderr
+			c.Dir = repoRoot
+			err = c.Run()
+			if err == nil {
+				target = "FETCH_HEAD"
+			} else {
+				utils.LogErr.Warnf("failed to fetch '%s' of '%s', using the cached checkout\n", rev, repoUrl)
+			}
+		}
+
+		// reset in case something or someone tampered with the cached checkout
+		c := exec.CommandContext(ctx, "git", "reset", "--quiet", "--hard", target)
+		c.Stderr = os.Stderr
+		c.Dir = repoRoot
+		err = c.Run()
+		if err != nil {
+			return "", CreateErr(nil, err, "failed to reset '%s' of '%s'", rev, repoUrl)
+		}
+	}
+
+	return repoRoot, nil
+}
+
+var reCommitSha = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// graphRefPortError explains a failed connection to a group node whose graph
+// is referenced from another file. Such errors are most likely caused by a
+// change of the inputs or outputs of the referenced graph.
+func graphRefPortError(err error, nodes ...NodeBaseInterface) error {
+	for _, n := range nodes {
+		refNode, ok := n.(HasGraphRefInterface)
+		if !ok || refNode.GetGraphRef() == nil {
+			continue
+		}
+		return CreateErr(nil, err, "node '%s' doesn't match the ports of its referenced graph '%s'", n.GetId(), refNode.GetGraphRef().Ref).
+			SetHint("the inputs or outputs of '%s' changed, update the connections of node '%s' to match them", refNode.GetGraphRef().Ref, n.GetId())
+	}
+	return err
+}
+
+// isInlineGroupNode returns true for group nodes whose graph is defined inline.
+// Their ports are defined by the same graph file, so connections to them are not validated.
+func isInlineGroupNode(node NodeBaseInterface) bool {
+	if !isGroupNode(node) {
+		return false
+	}
+	refNode, ok := node.(HasGraphRefInterface)
+	return !ok || refNode.GetGraphRef() == nil
+}
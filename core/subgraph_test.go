@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitGraphRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		repoUrl string
+		path    string
+		rev     string
+		isGit   bool
+	}{
+		{
+			ref:     "https://github.com/owner/repo.git/graphs/build.act@v1.2.0",
+			repoUrl: "https://github.com/owner/repo.git",
+			path:    "graphs/build.act",
+			rev:     "v1.2.0",
+			isGit:   true,
+		},
+		{
+			ref:     "https://github.com/owner/repo.git/build.act",
+			repoUrl: "https://github.com/owner/repo.git",
+			path:    "build.act",
+			isGit:   true,
+		},
+		{
+			ref:     "git@github.com:owner/repo.git/graphs/build.act@main",
+			repoUrl: "git@github.com:owner/repo.git",
+			path:    "graphs/build.act",
+			rev:     "main",
+			isGit:   true,
+		},
+		{
+			ref: "graphs/build.act",
+		},
+		{
+			ref: "https://example.com/build.act",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			repoUrl, path, rev, isGit := parseGitGraphRef(tt.ref)
+			assert.Equal(t, tt.isGit, isGit)
+			assert.Equal(t, tt.repoUrl, repoUrl)
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.rev, rev)
+		})
+	}
+}
+
+func TestFetchGraphRepoUpdatesBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := filepath.Join(t.TempDir(), "graphs.git")
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(content string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(repo, "build.act"), []byte(content), 0644))
+		git("add", "build.act")
+		git("commit", "--quiet", "-m", content)
+	}
+
+	assert.NoError(t, os.MkdirAll(repo, 0755))
+	git("init", "--quiet", "-b", "main")
+	commit("first")
+
+	readGraph := func() string {
+		root, err := fetchGraphRepo(context.Background(), "file://"+repo, "main")
+		assert.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(root, "build.act"))
+		assert.NoError(t, err)
+		return string(content)
+	}
+
+	assert.Equal(t, "first", readGraph())
+
+	// the cached clone follows the branch
+	commit("second")
+	assert.Equal(t, "second", readGraph())
+
+	// a cancelled run stops cloning, the next run clones the repository again
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := fetchGraphRepo(ctx, "file://"+repo, "other")
+	assert.Error(t, err)
+	_, err = fetchGraphRepo(context.Background(), "file://"+repo, "other")
+	assert.ErrorContains(t, err, "failed to check out 'other'")
+}
+
+func TestResolveGraphRefAgainstGraphDir(t *testing.T) {
+	dir := t.TempDir()
+
+	graphRef, err := resolveGraphRef("./shared/lib.act", GraphLoadContext{Dir: dir})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "shared", "lib.act"), graphRef.Path)
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	graphRef, err = resolveGraphRef("lib.act", GraphLoadContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(cwd, "lib.act"), graphRef.Path)
+}
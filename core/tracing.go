@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actionforge/actrun-cli/build"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer exports one span per executed node to an OpenTelemetry collector via OTLP/HTTP.
+//
+// The spans of a run are children of a single run span. Nodes inside a group are
+// children of the group's span, and nodes of a concurrent iteration are children of the
+// span of the node that created the iteration's execution state.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+
+	runCtx  context.Context
+	runSpan trace.Span
+
+	lock sync.Mutex
+	// spans of nodes that haven't finished yet, by `traceSpanKey`
+	open map[string]trace.Span
+}
+
+// NewTracer creates a tracer that sends its spans to `endpoint`, the base URL of the collector.
+// As with OTEL_EXPORTER_OTLP_ENDPOINT, `/v1/traces` is appended to the path unless it is already present.
+func NewTracer(ctx context.Context, endpoint string) (*Tracer, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, CreateErr(nil, err, "invalid OTLP endpoint '%s'", endpoint).
+			SetHint("use the base URL of the collector, like 'http://localhost:4318'")
+	}
+	if !strings.HasSuffix(u.Path, "/v1/traces") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/traces"
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(u.String()))
+	if err != nil {
+		return nil, CreateErr(nil, err, "failed to create OTLP exporter")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "actrun"),
+			attribute.String("service.version", build.GetAppVersion()),
+		)),
+	)
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer("github.com/actionforge/actrun-cli"),
+		open:     map[string]trace.Span{},
+	}, nil
+}
+
+func (t *Tracer) runStarted(ec *ExecutionState) {
+	t.runCtx, t.runSpan = t.tracer.Start(context.Background(), "run "+ec.GraphFile,
+		trace.WithAttributes(
+			attribute.String("actrun.run.id", ec.Id),
+			attribute.String("actrun.graph.file", ec.GraphFile),
+		),
+	)
+}
+
+func (t *Tracer) runFinished(ec *ExecutionState, runErr error) {
+	t.lock.Lock()
+	// Nodes that are still open didn't get the chance to finish, eg. after a panic
+	for key, span := range t.open {
+		span.End()
+		delete(t.open, key)
+	}
+	t.lock.Unlock()
+
+	t.runSpan.SetAttributes(attribute.String("actrun.run.status", string(getRunStatus(ec, runErr))))
+	setSpanError(t.runSpan, runErr)
+	t.runSpan.End()
+}
+
+// nodeEntered starts the span of a node. Leaving a group ends the group's span
+// instead, as its span covers the whole inner graph.
+func (t *Tracer) nodeEntered(ec *ExecutionState, node NodeBaseInterface, port InputId) {
+	if isGroupNode(node) && !isGroupEntry(node, port) {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	parentCtx := t.runCtx
+	if parent := t.parentSpanLocked(ec); parent != nil {
+		parentCtx = trace.ContextWithSpan(t.runCtx, parent)
+	}
+
+	_, span := t.tracer.Start(parentCtx, node.GetFullPath(),
+		trace.WithAttributes(
+			attribute.String("actrun.node.id", node.GetId()),
+			attribute.String("actrun.node.type", node.GetNodeTypeId()),
+			attribute.String("actrun.node.full_path", node.GetFullPath()),
+			attribute.String("actrun.node.port", string(port)),
+			attribute.String("actrun.execution.id", ec.Id),
+		),
+	)
+	t.open[traceSpanKey(ec, node)] = span
+}
+
+// nodeFinished ends the span of a node once it hands over to `output`, or when
+// it returned without handing over, in which case `output` is empty.
+func (t *Tracer) nodeFinished(ec *ExecutionState, node NodeBaseInterface, output OutputId, err error) {
+	if node == nil {
+		return
+	}
+
+	// a group hands over to its inner graph when entered, which is not the end of the group
+	if output != "" && isGroupNode(node) && isGroupEntry(node, InputId(output)) {
+		return
+	}
+
+	t.lock.Lock()
+	key := traceSpanKey(ec, node)
+	span, ok := t.open[key]
+	delete(t.open, key)
+	t.lock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if output != "" {
+		span.SetAttributes(attribute.String("actrun.node.output", string(output)))
+	}
+	setSpanError(span, err)
+	span.End()
+}
+
+// parentSpanLocked returns the open span of the innermost group or the node
+// that created the current execution state. The nodes of the hierarchy may
+// belong to the current or one of the parent execution states.
+func (t *Tracer) parentSpanLocked(ec *ExecutionState) trace.Span {
+	for i := len(ec.Hierarchy) - 1; i >= 0; i-- {
+		for state := ec; state != nil; state = state.ParentExecution {
+			span, ok := t.open[traceSpanKey(state, ec.Hierarchy[i])]
+			if ok {
+				return span
+			}
+		}
+	}
+	return nil
+}
+
+// Shutdown flushes all pending spans to the collector.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err := t.provider.Shutdown(ctx)
+	if err != nil {
+		return CreateErr(nil, err, "failed to export traces").
+			SetHint("check that the OTLP collector is reachable")
+	}
+	return nil
+}
+
+func traceSpanKey(ec *ExecutionState, node NodeBaseInterface) string {
+	return ec.Id + ":" + node.GetFullPath()
+}
+
+func setSpanError(span trace.Span, err error) {
+	if err == nil || IsLoopSignal(err) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	var leafErr *LeafError
+	if errors.As(err, &leafErr) {
+		hint := getErrorHint(leafErr)
+		if hint != "" {
+			span.SetAttributes(attribute.String("actrun.error.hint", hint))
+		}
+	}
+}
+
+func isGroupNode(node NodeBaseInterface) bool {
+	return strings.HasPrefix(node.GetNodeTypeId(), "core/group@")
+}
+
+// isGroupEntry returns true if `port` is an input of the group, which enters the group.
+// Any other port leaves the group.
+func isGroupEntry(node NodeBaseInterface, port InputId) bool {
+	inputs, ok := node.(HasInputsInterface)
+	if !ok {
+		return false
+	}
+	_, ok = inputs.GetInputDefs()[port]
+	return ok
+}
@@ -0,0 +1,304 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/actionforge/actrun-cli/utils"
+	"github.com/google/uuid"
+)
+
+// Annotation is an error, warning or notice that a process reported with a workflow
+// command like `::error file=app.js,line=1::Missing semicolon`.
+type Annotation struct {
+	Level     string
+	Message   string
+	Title     string
+	File      string
+	Line      int
+	EndLine   int
+	Col       int
+	EndColumn int
+}
+
+// Annotations are the annotations reported during a run. They are shared by all
+// execution states and summarized once the graph finished.
+type Annotations struct {
+	lock sync.Mutex
+	list []Annotation
+}
+
+func (a *Annotations) add(annotation Annotation) {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.list = append(a.list, annotation)
+}
+
+// printSummary prints the annotations of the run. For a GitHub runner, they are printed as
+// workflow commands again, so the runner creates the same annotations for the workflow run.
+func (a *Annotations) printSummary(isGitHubRunner bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if len(a.list) == 0 {
+		return
+	}
+
+	if isGitHubRunner {
+		for _, annotation := range a.list {
+			utils.LogOut.Infof("%s\n", annotation.command())
+		}
+		return
+	}
+
+	utils.LogOut.Infof("📝 Annotations:\n")
+	for _, annotation := range a.list {
+		location := annotation.File
+		if location != "" && annotation.Line > 0 {
+			location += ":" + strconv.Itoa(annotation.Line)
+		}
+		if location != "" {
+			location = " (" + location + ")"
+		}
+		title := ""
+		if annotation.Title != "" {
+			title = annotation.Title + ": "
+		}
+		utils.LogOut.Infof("  %s: %s%s%s\n", annotation.Level, title, annotation.Message, location)
+	}
+}
+
+// command returns the workflow command that reports the annotation.
+func (a Annotation) command() string {
+	var properties []string
+	for _, p := range []struct {
+		key   string
+		value string
+	}{
+		{"title", a.Title},
+		{"file", a.File},
+		{"line", formatAnnotationInt(a.Line)},
+		{"endLine", formatAnnotationInt(a.EndLine)},
+		{"col", formatAnnotationInt(a.Col)},
+		{"endColumn", formatAnnotationInt(a.EndColumn)},
+	} {
+		if p.value != "" {
+			properties = append(properties, p.key+"="+escapeWorkflowCommandProperty(p.value))
+		}
+	}
+
+	command := "::" + a.Level
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	return command + "::" + escapeWorkflowCommandData(a.Message)
+}
+
+func formatAnnotationInt(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+// `::name key=value,key=value::message`
+var reWorkflowCommand = regexp.MustCompile(`^::([A-Za-z-]+)(?: ([^:]*))?::(.*)$`)
+
+// WorkflowCommandWriter parses the workflow commands that a process writes to stdout, like
+// GitHub Actions does, and writes all other lines to `out`. The deprecated `set-output` and
+// `save-state` commands are written to the files of GITHUB_OUTPUT and GITHUB_STATE.
+// https://docs.github.com/en/actions/reference/workflows-and-actions/workflow-commands
+type WorkflowCommandWriter struct {
+	c   *ExecutionState
+	out io.Writer
+	env map[string]string
+
+	buf []byte
+	// the token that resumes the processing of commands after `stop-commands`
+	stopToken string
+}
+
+// NewWorkflowCommandWriter returns a writer that parses the workflow commands written
+// to it. `env` are the env vars of the process. `Close` must be called once the
+// process finished.
+func NewWorkflowCommandWriter(c *ExecutionState, out io.Writer, env map[string]string) *WorkflowCommandWriter {
+	return &WorkflowCommandWriter{
+		c:   c,
+		out: out,
+		env: env,
+	}
+}
+
+func (w *WorkflowCommandWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+
+		err := w.processLine(line)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close processes the last line if it doesn't end with a newline.
+func (w *WorkflowCommandWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := string(w.buf)
+	w.buf = nil
+	return w.processLine(line)
+}
+
+func (w *WorkflowCommandWriter) processLine(line string) error {
+	trimmed := strings.TrimRight(line, "\r\n")
+
+	// after `stop-commands`, all lines are printed until `::<token>::`
+	if w.stopToken != "" {
+		if trimmed == "::"+w.stopToken+"::" {
+			w.stopToken = ""
+			return w.forwardToRunner(line)
+		}
+		_, err := io.WriteString(w.out, line)
+		return err
+	}
+
+	m := reWorkflowCommand.FindStringSubmatch(trimmed)
+	if m == nil {
+		_, err := io.WriteString(w.out, line)
+		return err
+	}
+
+	command, data := m[1], unescapeWorkflowCommandData(m[3])
+	properties := map[string]string{}
+	if m[2] != "" {
+		for _, property := range strings.Split(m[2], ",") {
+			key, value, ok := strings.Cut(property, "=")
+			if ok {
+				properties[strings.TrimSpace(key)] = unescapeWorkflowCommandProperty(value)
+			}
+		}
+	}
+
+	switch command {
+	case "add-mask":
+		// forwarded before the value is masked, otherwise the runner would receive `***`
+		err := w.forwardToRunner(line)
+		if err != nil {
+			return err
+		}
+		utils.AddLogMask(data)
+	case "error", "warning", "notice":
+		annotation := Annotation{
+			Level:     command,
+			Message:   data,
+			Title:     properties["title"],
+			File:      properties["file"],
+			Line:      parseAnnotationInt(properties["line"]),
+			EndLine:   parseAnnotationInt(properties["endLine"]),
+			Col:       parseAnnotationInt(properties["col"]),
+			EndColumn: parseAnnotationInt(properties["endColumn"]),
+		}
+		w.c.Annotations.add(annotation)
+		_, err := fmt.Fprintf(w.out, "##[%s]%s\n", command, data)
+		return err
+	case "group":
+		_, err := fmt.Fprintf(w.out, "%s%s\n", utils.LogGhStartGroup, data)
+		return err
+	case "endgroup":
+		_, err := fmt.Fprintf(w.out, "%s\n", utils.LogGhEndGroup)
+		return err
+	case "debug":
+		utils.LogOut.Debugf("##[debug]%s\n", data)
+	case "stop-commands":
+		w.stopToken = data
+		return w.forwardToRunner(line)
+	case "set-output":
+		utils.LogErr.Warnf("The `set-output` command is deprecated, write to the GITHUB_OUTPUT file instead.\n")
+		return w.appendToFileCommand("GITHUB_OUTPUT", properties["name"], data)
+	case "save-state":
+		utils.LogErr.Warnf("The `save-state` command is deprecated, write to the GITHUB_STATE file instead.\n")
+		return w.appendToFileCommand("GITHUB_STATE", properties["name"], data)
+	default:
+		// unknown commands are printed like any other line
+		_, err := io.WriteString(w.out, line)
+		return err
+	}
+	return nil
+}
+
+// forwardToRunner writes a command that the GitHub Actions runner must process as well, since
+// it parses the output of actrun. Otherwise it wouldn't mask the values of `add-mask` in later
+// steps, and it would parse the lines after `stop-commands` as commands.
+func (w *WorkflowCommandWriter) forwardToRunner(line string) error {
+	if !w.c.IsGitHubRunner {
+		return nil
+	}
+	_, err := io.WriteString(w.out, line)
+	return err
+}
+
+// appendToFileCommand appends a value to the file of a file command like GITHUB_OUTPUT.
+func (w *WorkflowCommandWriter) appendToFileCommand(envName string, name string, value string) error {
+	path := w.env[envName]
+	if path == "" || name == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return CreateErr(w.c, err, "unable to open file set in %s", envName)
+	}
+	defer f.Close()
+
+	delimiter := "ghadelimiter_" + uuid.New().String()
+	_, err = fmt.Fprintf(f, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+	if err != nil {
+		return CreateErr(w.c, err, "unable to write file set in %s", envName)
+	}
+	return nil
+}
+
+func parseAnnotationInt(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+var (
+	workflowCommandDataEscaper       = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	workflowCommandDataUnescaper     = strings.NewReplacer("%25", "%", "%0D", "\r", "%0A", "\n")
+	workflowCommandPropertyEscaper   = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	workflowCommandPropertyUnescaper = strings.NewReplacer("%25", "%", "%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",")
+)
+
+func escapeWorkflowCommandData(s string) string {
+	return workflowCommandDataEscaper.Replace(s)
+}
+
+func unescapeWorkflowCommandData(s string) string {
+	return workflowCommandDataUnescaper.Replace(s)
+}
+
+func escapeWorkflowCommandProperty(s string) string {
+	return workflowCommandPropertyEscaper.Replace(s)
+}
+
+func unescapeWorkflowCommandProperty(s string) string {
+	return workflowCommandPropertyUnescaper.Replace(s)
+}
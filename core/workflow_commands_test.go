@@ -0,0 +1,95 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowCommandWriter(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output")
+
+	c := &ExecutionState{Annotations: &Annotations{}}
+	var out bytes.Buffer
+	w := NewWorkflowCommandWriter(c, &out, map[string]string{"GITHUB_OUTPUT": outputFile})
+
+	input := strings.Join([]string{
+		"::group::Build",
+		"compiling",
+		"::error file=app.js,line=3,title=Syntax%2C error::Missing semicolon%0Aat line 3",
+		"::endgroup::",
+		"::stop-commands::pause-token",
+		"::warning::not a command",
+		"::pause-token::",
+		"::notice::resumed",
+		"::set-output name=version::1.2.3",
+		"::unknown::kept",
+		"no newline at the end",
+	}, "\n")
+
+	// write in chunks that split the lines
+	for len(input) > 0 {
+		n := min(7, len(input))
+		_, err := w.Write([]byte(input[:n]))
+		assert.NoError(t, err)
+		input = input[n:]
+	}
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, strings.Join([]string{
+		"##[group]Build",
+		"compiling",
+		"##[error]Missing semicolon\nat line 3",
+		"##[endgroup]",
+		"::warning::not a command",
+		"##[notice]resumed",
+		"::unknown::kept",
+		"no newline at the end",
+	}, "\n"), out.String())
+
+	assert.Equal(t, []Annotation{
+		{Level: "error", Message: "Missing semicolon\nat line 3", Title: "Syntax, error", File: "app.js", Line: 3},
+		{Level: "notice", Message: "resumed"},
+	}, c.Annotations.list)
+
+	output, err := os.ReadFile(outputFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), "version<<ghadelimiter_")
+	assert.Contains(t, string(output), "\n1.2.3\n")
+}
+
+func TestAnnotationCommand(t *testing.T) {
+	a := Annotation{Level: "warning", Message: "100% done\nnext", Title: "a:b", File: "main.go", Line: 10, Col: 2}
+	assert.Equal(t, "::warning title=a%3Ab,file=main.go,line=10,col=2::100%25 done%0Anext", a.command())
+
+	assert.Equal(t, "::notice::done", Annotation{Level: "notice", Message: "done"}.command())
+}
+
+func TestWorkflowCommandWriterOnGitHub(t *testing.T) {
+	c := &ExecutionState{Annotations: &Annotations{}, IsGitHubRunner: true}
+	var out bytes.Buffer
+	w := NewWorkflowCommandWriter(c, &out, map[string]string{})
+
+	// the runner parses the output of actrun, so it must see the commands that affect later lines
+	_, err := w.Write([]byte(strings.Join([]string{
+		"::add-mask::forwarded-secret",
+		"::stop-commands::pause-token",
+		"::warning::not a command",
+		"::pause-token::",
+		"",
+	}, "\n")))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, strings.Join([]string{
+		"::add-mask::forwarded-secret",
+		"::stop-commands::pause-token",
+		"::warning::not a command",
+		"::pause-token::",
+		"",
+	}, "\n"), out.String())
+}
@@ -9,6 +9,8 @@ import "github.com/actionforge/actrun-cli/core" // Conditional execution node fo
 // Boolean input that determines which branch to execute.
 const Core_branch_v1_Input_condition core.InputId = "condition"
 const Core_branch_v1_Input_exec core.InputId = "exec"
+// An expression that is evaluated instead of the condition input, like `github.event_name == 'push'`.
+const Core_branch_v1_Input_expression core.InputId = "expression"
 
 // Outputs (o) ==> 
 
@@ -14,6 +14,10 @@ const Core_concurrent_for_each_loop_v1_Input_worker_count core.InputId = "worker
 
 // Outputs (o) ==> 
 
+// The key of the first item whose iteration stopped the loop. Only set if the loop was stopped by a Loop Break node.
+const Core_concurrent_for_each_loop_v1_Output_break_key core.OutputId = "break_key"
+// True if the loop was stopped by a Loop Break node.
+const Core_concurrent_for_each_loop_v1_Output_broken core.OutputId = "broken"
 // Executes on each iteration.
 const Core_concurrent_for_each_loop_v1_Output_exec_body core.OutputId = "exec-body"
 // Executes upon completion of the loop.
@@ -17,6 +17,10 @@ const Core_concurrent_for_loop_v1_Input_worker_count core.InputId = "worker_coun
 
 // Outputs (o) ==> 
 
+// The lowest index of the iterations that stopped the loop. Only set if the loop was stopped by a Loop Break node.
+const Core_concurrent_for_loop_v1_Output_break_index core.OutputId = "break_index"
+// True if the loop was stopped by a Loop Break node.
+const Core_concurrent_for_loop_v1_Output_broken core.OutputId = "broken"
 // Executes on each iteration.
 const Core_concurrent_for_loop_v1_Output_exec_body core.OutputId = "exec-body"
 // Executes upon completion of the loop.
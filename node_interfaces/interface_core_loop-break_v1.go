@@ -0,0 +1,11 @@
+// Code generated by actrun. DO NOT EDIT.
+
+package node_interfaces
+
+import "github.com/actionforge/actrun-cli/core" // Stops a loop from within its body.
+
+// ==> (o) Inputs
+
+const Core_loop_break_v1_Input_exec core.InputId = "exec"
+// The id of the loop to stop. If empty, the nearest loop is stopped.
+const Core_loop_break_v1_Input_loop core.InputId = "loop"
@@ -0,0 +1,11 @@
+// Code generated by actrun. DO NOT EDIT.
+
+package node_interfaces
+
+import "github.com/actionforge/actrun-cli/core" // Skips the rest of the current iteration of a loop.
+
+// ==> (o) Inputs
+
+const Core_loop_continue_v1_Input_exec core.InputId = "exec"
+// The id of the loop to continue. If empty, the nearest loop is continued.
+const Core_loop_continue_v1_Input_loop core.InputId = "loop"
@@ -0,0 +1,20 @@
+// Code generated by actrun. DO NOT EDIT.
+
+package node_interfaces
+
+import "github.com/actionforge/actrun-cli/core" // Limits how many concurrent branches use a resource at the same time.
+
+// ==> (o) Inputs
+
+// How much of the capacity of the semaphore is acquired.
+const Core_semaphore_v1_Input_count core.InputId = "count"
+const Core_semaphore_v1_Input_exec core.InputId = "exec"
+// The name of the semaphore as defined in the graph.
+const Core_semaphore_v1_Input_name core.InputId = "name"
+
+// Outputs (o) ==> 
+
+// Executes while the semaphore is held.
+const Core_semaphore_v1_Output_exec_acquired core.OutputId = "exec-acquired"
+// Executes after the semaphore was released.
+const Core_semaphore_v1_Output_exec_released core.OutputId = "exec-released"
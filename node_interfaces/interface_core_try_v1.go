@@ -0,0 +1,20 @@
+// Code generated by actrun. DO NOT EDIT.
+
+package node_interfaces
+
+import "github.com/actionforge/actrun-cli/core" // Catches errors of a branch and runs a cleanup branch in any case.
+
+// ==> (o) Inputs
+
+const Core_try_v1_Input_exec core.InputId = "exec"
+
+// Outputs (o) ==> 
+
+// The message of the caught error.
+const Core_try_v1_Output_error core.OutputId = "error"
+// Executes if the 'Try' branch failed with an error.
+const Core_try_v1_Output_exec_catch core.OutputId = "exec-catch"
+// Always executes after the 'Try' and 'Catch' branch, even if the run was cancelled.
+const Core_try_v1_Output_exec_finally core.OutputId = "exec-finally"
+// Executes the branch whose errors are caught.
+const Core_try_v1_Output_exec_try core.OutputId = "exec-try"
@@ -0,0 +1,27 @@
+// Code generated by actrun. DO NOT EDIT.
+
+package node_interfaces
+
+import "github.com/actionforge/actrun-cli/core" // Executes its body as long as a condition is true.
+
+// ==> (o) Inputs
+
+// The loop continues as long as the condition is true.
+const Core_while_loop_v1_Input_condition core.InputId = "condition"
+// Checks the condition after each iteration instead of before, so the body is executed at least once.
+const Core_while_loop_v1_Input_do_while core.InputId = "do_while"
+// Executes the loop.
+const Core_while_loop_v1_Input_exec core.InputId = "exec"
+// An expression that is evaluated instead of the condition input, like `env.STATUS != '200'`.
+const Core_while_loop_v1_Input_expression core.InputId = "expression"
+// The loop fails once it reached this number of iterations. Use 0 for no limit.
+const Core_while_loop_v1_Input_max_iterations core.InputId = "max_iterations"
+
+// Outputs (o) ==> 
+
+// Executes on each iteration.
+const Core_while_loop_v1_Output_exec_body core.OutputId = "exec-body"
+// Executes once the condition is false.
+const Core_while_loop_v1_Output_exec_completed core.OutputId = "exec-completed"
+// Current iteration index, starting at 0.
+const Core_while_loop_v1_Output_index core.OutputId = "index"
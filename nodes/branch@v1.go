@@ -2,6 +2,7 @@ package nodes
 
 import (
 	_ "embed"
+	"strings"
 
 	"github.com/actionforge/actrun-cli/core"
 	ni "github.com/actionforge/actrun-cli/node_interfaces"
@@ -18,7 +19,7 @@ type BranchNode struct {
 }
 
 func (n *BranchNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
-	condition, err := core.InputValueById[bool](c, n, ni.Core_branch_v1_Input_condition)
+	condition, err := n.evaluateCondition(c)
 	if err != nil {
 		return err
 	}
@@ -37,6 +38,27 @@ func (n *BranchNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, p
 	return nil
 }
 
+func (n *BranchNode) evaluateCondition(c *core.ExecutionState) (bool, error) {
+	return evaluateExpressionOrCondition(c, n, ni.Core_branch_v1_Input_expression, ni.Core_branch_v1_Input_condition)
+}
+
+// evaluateExpressionOrCondition evaluates the expression input of a node if it's set,
+// otherwise it returns the value of the condition input.
+func evaluateExpressionOrCondition(c *core.ExecutionState, n core.NodeWithInputs, expressionId core.InputId, conditionId core.InputId) (bool, error) {
+	// The expression input has no socket, so it's read as written. Read as
+	// an input value, any `${{ }}` in it would be evaluated to a string.
+	expression, _ := n.GetInputValues()[expressionId].(string)
+	if strings.TrimSpace(expression) != "" {
+		ok, err := core.EvaluateCondition(c, expression)
+		if err != nil {
+			return false, core.CreateErr(c, err, "unable to evaluate the expression '%s'", expression)
+		}
+		return ok, nil
+	}
+
+	return core.InputValueById[bool](c, n, conditionId)
+}
+
 func init() {
 	err := core.RegisterNodeFactory(ifDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
 		return &BranchNode{}, nil
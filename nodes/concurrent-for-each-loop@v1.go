@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"errors"
 	"sync"
+	"sync/atomic"
 
 	"github.com/actionforge/actrun-cli/core"
 	ni "github.com/actionforge/actrun-cli/node_interfaces"
@@ -35,24 +36,44 @@ func (n *ConcurrentForEachLoopNode) ExecuteImpl(c *core.ExecutionState, inputId
 		workerCount = 1
 	}
 
-	taskCh := make(chan [2]any, workerCount)
+	// the item's position is kept to find the first item whose iteration broke the loop
+	type task struct {
+		key   any
+		value any
+		pos   int
+	}
+
+	taskCh := make(chan task, workerCount)
 	wg := sync.WaitGroup{}
 	var mutex sync.Mutex
 	var firstError error
 
+	// Once the loop is broken, or an outer loop is signaled, no more iterations are started.
+	// The iterations that already started are finished.
+	var stopped atomic.Bool
+	var broken bool
+	var breakKey any
+	var breakPos int
+	var outerSignal error
+
+	pos := 0
 	iter := func(key, value any) {
-		taskCh <- [2]any{key, value}
+		taskCh <- task{key, value, pos}
+		pos++
 	}
 
 	for w := 0; w < workerCount; w++ {
 		fn := func() {
 			for task := range taskCh {
-				key := task[0]
-				value := task[1]
+				key := task.key
+				value := task.value
 
 				if c.IsCancelled() {
 					return
 				}
+				if stopped.Load() {
+					continue
+				}
 
 				nti := c.PushNewExecutionState(n)
 				err := n.Outputs.SetOutputValue(nti, ni.Core_concurrent_for_each_loop_v1_Output_key, key, core.SetOutputValueOpts{})
@@ -84,6 +105,29 @@ func (n *ConcurrentForEachLoopNode) ExecuteImpl(c *core.ExecutionState, inputId
 				}
 
 				err = n.Execute(ni.Core_concurrent_for_each_loop_v1_Output_exec_body, nti, nil)
+				if signal, ok := core.LoopSignalFor(n, err); ok {
+					if signal.Break {
+						mutex.Lock()
+						if !broken || task.pos < breakPos {
+							breakKey, breakPos = key, task.pos
+						}
+						broken = true
+						mutex.Unlock()
+
+						stopped.Store(true)
+					}
+					continue
+				} else if core.IsLoopSignal(err) {
+					// the outer loop receives the signal once all started iterations are finished
+					mutex.Lock()
+					if outerSignal == nil {
+						outerSignal = err
+					}
+					mutex.Unlock()
+
+					stopped.Store(true)
+					continue
+				}
 				if err != nil {
 					c.Cancel()
 
@@ -107,7 +151,7 @@ func (n *ConcurrentForEachLoopNode) ExecuteImpl(c *core.ExecutionState, inputId
 		}()
 	}
 
-	for iterable.Next() && !c.IsCancelled() {
+	for !stopped.Load() && iterable.Next() && !c.IsCancelled() {
 		iter(iterable.Key(), iterable.Value())
 	}
 
@@ -118,6 +162,21 @@ func (n *ConcurrentForEachLoopNode) ExecuteImpl(c *core.ExecutionState, inputId
 	if firstError != nil {
 		return firstError
 	}
+	if outerSignal != nil {
+		return outerSignal
+	}
+
+	err = n.Outputs.SetOutputValue(c, ni.Core_concurrent_for_each_loop_v1_Output_broken, broken, core.SetOutputValueOpts{})
+	if err != nil {
+		return err
+	}
+
+	if broken {
+		err = n.Outputs.SetOutputValue(c, ni.Core_concurrent_for_each_loop_v1_Output_break_key, breakKey, core.SetOutputValueOpts{})
+		if err != nil {
+			return err
+		}
+	}
 
 	err = n.Execute(ni.Core_concurrent_for_each_loop_v1_Output_exec_completed, c, nil)
 	if err != nil {
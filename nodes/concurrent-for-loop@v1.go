@@ -3,6 +3,7 @@ package nodes
 import (
 	_ "embed"
 	"sync"
+	"sync/atomic"
 
 	"github.com/actionforge/actrun-cli/core"
 	ni "github.com/actionforge/actrun-cli/node_interfaces"
@@ -56,6 +57,13 @@ func (n *ConcurrentLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.In
 	var mutex sync.Mutex
 	var firstError error
 
+	// Once the loop is broken, or an outer loop is signaled, no more iterations are started.
+	// The iterations that already started are finished.
+	var stopped atomic.Bool
+	var broken bool
+	var breakIndex int
+	var outerSignal error
+
 	count := lastIndex - firstIndex + 1
 	nti := []*core.ExecutionState{}
 	for i := 0; i < count; i++ {
@@ -71,6 +79,9 @@ func (n *ConcurrentLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.In
 					if c.IsCancelled() {
 						return
 					}
+					if stopped.Load() {
+						continue
+					}
 
 					ctx := nti[i-firstIndex]
 
@@ -89,6 +100,29 @@ func (n *ConcurrentLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.In
 					}
 
 					err = n.Execute(ni.Core_concurrent_for_loop_v1_Output_exec_body, ctx, nil)
+					if signal, ok := core.LoopSignalFor(n, err); ok {
+						if signal.Break {
+							mutex.Lock()
+							if !broken || i < breakIndex {
+								breakIndex = i
+							}
+							broken = true
+							mutex.Unlock()
+
+							stopped.Store(true)
+						}
+						continue
+					} else if core.IsLoopSignal(err) {
+						// the outer loop receives the signal once all started iterations are finished
+						mutex.Lock()
+						if outerSignal == nil {
+							outerSignal = err
+						}
+						mutex.Unlock()
+
+						stopped.Store(true)
+						continue
+					}
 					if err != nil {
 						c.Cancel()
 
@@ -118,7 +152,7 @@ func (n *ConcurrentLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.In
 			}
 		}
 
-		for i := firstIndex; i <= lastIndex && !c.IsCancelled(); i++ {
+		for i := firstIndex; i <= lastIndex && !c.IsCancelled() && !stopped.Load(); i++ {
 			taskCh <- i
 		}
 		close(taskCh)
@@ -129,6 +163,21 @@ func (n *ConcurrentLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.In
 	if firstError != nil {
 		return firstError
 	}
+	if outerSignal != nil {
+		return outerSignal
+	}
+
+	err = n.Outputs.SetOutputValue(c, ni.Core_concurrent_for_loop_v1_Output_broken, broken, core.SetOutputValueOpts{})
+	if err != nil {
+		return err
+	}
+
+	if broken {
+		err = n.Outputs.SetOutputValue(c, ni.Core_concurrent_for_loop_v1_Output_break_index, breakIndex, core.SetOutputValueOpts{})
+		if err != nil {
+			return err
+		}
+	}
 
 	err = n.Execute(ni.Core_concurrent_for_loop_v1_Output_exec_completed, c, nil)
 	if err != nil {
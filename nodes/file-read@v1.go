@@ -35,10 +35,11 @@ func (n *FileReadNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId,
 		Reader:     fp,
 		Length:     core.GetReaderLength(fp),
 	}
-	defer dsf.CloseStreamAndIgnoreError()
 
+	// the executor closes the stream once no node can read it anymore
 	err = n.Outputs.SetOutputValue(c, ni.Core_file_read_v1_Output_data, dsf, core.SetOutputValueOpts{})
 	if err != nil {
+		dsf.CloseStreamAndIgnoreError()
 		return err
 	}
 
@@ -19,6 +19,10 @@ type ForEachLoopNode struct {
 	run bool
 }
 
+func (n *ForEachLoopNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeRestart
+}
+
 func (n *ForEachLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
 
 	if inputId == ni.Core_for_each_loop_v1_Input_exec_break {
@@ -50,12 +54,21 @@ func (n *ForEachLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.Input
 		return err
 	}
 
+	leaveLoop := c.EnterLoop(n)
 	for n.run && iterable.Next() && !c.IsCancelled() {
 		err := iter(iterable.Key(), iterable.Value())
+		if signal, ok := core.LoopSignalFor(n, err); ok {
+			if signal.Break {
+				break
+			}
+			continue
+		}
 		if err != nil {
+			leaveLoop()
 			return err
 		}
 	}
+	leaveLoop()
 
 	err = n.Execute(ni.Core_for_each_loop_v1_Output_exec_completed, c, nil)
 	if err != nil {
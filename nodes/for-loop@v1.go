@@ -19,6 +19,10 @@ type LoopNode struct {
 	run bool
 }
 
+func (n *LoopNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeRestart
+}
+
 func (n *LoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
 
 	if inputId == ni.Core_for_each_loop_v1_Input_exec_break {
@@ -44,18 +48,28 @@ func (n *LoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, pre
 
 	_, ok := n.GetExecutionTarget(ni.Core_for_loop_v1_Output_exec_body)
 	if ok {
+		leaveLoop := c.EnterLoop(n)
 		for i := firstIndex; i <= lastIndex && !c.IsCancelled() && n.run; i++ {
 
 			err = n.Outputs.SetOutputValue(c, ni.Core_for_loop_v1_Output_index, i, core.SetOutputValueOpts{})
 			if err != nil {
+				leaveLoop()
 				return err
 			}
 
 			err = n.Execute(ni.Core_for_loop_v1_Output_exec_body, c, nil)
+			if signal, ok := core.LoopSignalFor(n, err); ok {
+				if signal.Break {
+					break
+				}
+				continue
+			}
 			if err != nil {
+				leaveLoop()
 				return err
 			}
 		}
+		leaveLoop()
 	}
 
 	err = n.Execute(ni.Core_for_loop_v1_Output_exec_completed, c, nil)
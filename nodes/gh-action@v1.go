@@ -5,12 +5,14 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/actionforge/actrun-cli/core"
@@ -45,6 +47,7 @@ type ActionType int
 const (
 	Docker ActionType = iota
 	Node
+	Composite
 )
 
 type DockerData struct {
@@ -60,34 +63,24 @@ type GhActionNode struct {
 	core.Executions
 
 	actionName      string
-	actionType      ActionType // docker or node
+	actionType      ActionType // docker, node or composite
 	actionRuns      ActionRuns
 	actionRunJsPath string
+	actionDir       string
+
+	// scripts of the pre and post steps of node actions, if any
+	actionRunPreJsPath  string
+	actionRunPostJsPath string
+
+	// steps and outputs of composite actions
+	compositeSteps   []compositeStep
+	compositeOutputs map[string]ActionOutput
 
 	Data DockerData
 }
 
 func (n *GhActionNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
-	currentEnvMap := c.GetContextEnvironMapCopy()
-
-	sysRunnerTempDir := currentEnvMap["RUNNER_TEMP"]
-	if sysRunnerTempDir == "" {
-		return core.CreateErr(c, nil, "RUNNER_TEMP is not set")
-	}
-
-	sysGhWorkspaceDir := currentEnvMap["GITHUB_WORKSPACE"]
-	if sysGhWorkspaceDir == "" {
-		return core.CreateErr(c, nil, "GITHUB_WORKSPACE is not set")
-	}
-
-	runnerToolCache := currentEnvMap["RUNNER_TOOL_CACHE"]
-	if runnerToolCache == "" {
-		return core.CreateErr(c, nil, "RUNNER_TOOL_CACHE is not set")
-	}
-
-	// turn inputs into env vars (INPUT_*)
-	// matches ContainerActionHandler.cs AddInputsToEnvironment() behavior
-	withInputs := ""
+	withInputs := map[string]string{}
 	for inputName, inputDef := range n.Inputs.GetInputDefs() {
 		if inputDef.Exec {
 			continue
@@ -98,21 +91,11 @@ func (n *GhActionNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId,
 			if err != nil {
 				return err
 			}
-
-			// convert input name to uppercase for INPUT_ env var
-			envKey := fmt.Sprintf("INPUT_%v", strings.ToUpper(string(inputName)))
-			currentEnvMap[envKey] = v
-			withInputs += fmt.Sprintf(" %s: %s\n", inputName, v)
+			withInputs[string(inputName)] = v
 		}
 	}
 
-	utils.LogOut.Infof("%sRun '%s (%s)'\n%s%s\n",
-		u.LogGhStartGroup,
-		n.GetId(),
-		n.GetNodeTypeId(),
-		withInputs,
-		u.LogGhEndGroup,
-	)
+	currentEnvMap := c.GetContextEnvironMapCopy()
 
 	// move env vars passed to node to env map
 	inputEnv, err := core.InputValueById[[]string](c, n, "env")
@@ -129,6 +112,94 @@ func (n *GhActionNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId,
 		}
 	}
 
+	outputs, ghEnvs, runErr := n.runAction(c, withInputs, currentEnvMap)
+
+	for outputId, outputDef := range n.OutputDefsClone() {
+		if outputDef.Exec {
+			continue
+		}
+		// all outputs in github actions are empty by default
+		err = n.SetOutputValue(c, outputId, "", core.SetOutputValueOpts{})
+		if err != nil {
+			return err
+		}
+	}
+
+	if runErr != nil {
+		err = n.Execute(ni.Core_gh_action_v1_Output_exec_err, c, runErr)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// transfer env vars to next node
+	nextEnvMap := c.GetContextEnvironMapCopy()
+	maps.Copy(nextEnvMap, ghEnvs)
+	c.SetContextEnvironMap(nextEnvMap)
+
+	for key, value := range outputs {
+		err = n.SetOutputValue(c, core.OutputId(key), value, core.SetOutputValueOpts{
+			NotExistsIsNoError: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = n.Execute(ni.Core_gh_action_v1_Output_exec_success, c, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runAction runs the action with the values of its inputs in the environment `currentEnvMap`.
+// It returns the outputs of the action and the env vars the action sets for the following steps
+// through the file commands GITHUB_ENV and GITHUB_PATH.
+func (n *GhActionNode) runAction(c *core.ExecutionState, withInputs map[string]string, currentEnvMap map[string]string) (map[string]string, map[string]string, error) {
+	sysRunnerTempDir := currentEnvMap["RUNNER_TEMP"]
+	if sysRunnerTempDir == "" {
+		return nil, nil, core.CreateErr(c, nil, "RUNNER_TEMP is not set")
+	}
+
+	sysGhWorkspaceDir := currentEnvMap["GITHUB_WORKSPACE"]
+	if sysGhWorkspaceDir == "" {
+		return nil, nil, core.CreateErr(c, nil, "GITHUB_WORKSPACE is not set")
+	}
+
+	runnerToolCache := currentEnvMap["RUNNER_TOOL_CACHE"]
+	if runnerToolCache == "" {
+		return nil, nil, core.CreateErr(c, nil, "RUNNER_TOOL_CACHE is not set")
+	}
+
+	withLog := ""
+	for _, inputName := range slices.Sorted(maps.Keys(withInputs)) {
+		withLog += fmt.Sprintf(" %s: %s\n", inputName, withInputs[inputName])
+	}
+
+	utils.LogOut.Infof("%sRun '%s (%s)'\n%s%s\n",
+		u.LogGhStartGroup,
+		n.GetId(),
+		n.GetNodeTypeId(),
+		withLog,
+		u.LogGhEndGroup,
+	)
+
+	// the steps of composite actions access the inputs through the `inputs` context
+	if n.actionType == Composite {
+		return n.runComposite(c, withInputs, currentEnvMap)
+	}
+
+	// turn inputs into env vars (INPUT_*)
+	// matches ContainerActionHandler.cs AddInputsToEnvironment() behavior
+	for inputName, v := range withInputs {
+		// convert input name to uppercase for INPUT_ env var
+		envKey := fmt.Sprintf("INPUT_%v", strings.ToUpper(inputName))
+		currentEnvMap[envKey] = v
+	}
+
 	// expose context vars (AllowList)
 	// matches GitHubContext.cs GetRuntimeEnvironmentVariables()
 	for contextName := range contextEnvAllowList {
@@ -141,14 +212,6 @@ func (n *GhActionNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId,
 		}
 	}
 
-	// init context parser for file commands (GITHUB_PATH, GITHUB_ENV)
-	ghContextParser := GhContextParser{}
-	ghEnvs, err := ghContextParser.Init(c, sysRunnerTempDir)
-	if err != nil {
-		return err
-	}
-	maps.Copy(currentEnvMap, ghEnvs)
-
 	// set dir envs in case they are missing
 	// https://github.com/actions/runner/blob/f467e9e1255530d3bf2e33f580d041925ab01951/src/Runner.Common/HostContext.cs#L288
 	if currentEnvMap["AGENT_TOOLSDIRECTORY"] == "" {
@@ -158,81 +221,151 @@ func (n *GhActionNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId,
 		currentEnvMap["RUNNER_TOOLSDIRECTORY"] = currentEnvMap["RUNNER_TOOL_CACHE"]
 	}
 
-	executionEnv := maps.Clone(currentEnvMap)
-
-	var runErr error
+	var preEntry, mainEntry, postEntry string
 	switch n.actionType {
 	case Docker:
-		runErr = n.ExecuteDocker(c, sysGhWorkspaceDir, executionEnv)
+		preEntry, mainEntry, postEntry = n.actionRuns.PreEntrypoint, n.actionRuns.Entrypoint, n.actionRuns.PostEntrypoint
 	case Node:
-		runErr = n.ExecuteNode(c, sysGhWorkspaceDir, executionEnv)
+		preEntry, mainEntry, postEntry = n.actionRunPreJsPath, n.actionRunJsPath, n.actionRunPostJsPath
 	default:
-		return core.CreateErr(c, nil, "unsupported action type: %v", n.actionType)
+		return nil, nil, core.CreateErr(c, nil, "unsupported action type: %v", n.actionType)
 	}
 
-	for outputId, outputDef := range n.OutputDefsClone() {
-		if outputDef.Exec {
-			continue
-		}
-		// all outputs in github actions are empty by default
-		err = n.SetOutputValue(c, outputId, "", core.SetOutputValueOpts{})
+	// the state the steps of the action save with GITHUB_STATE, which
+	// is passed to its following steps as STATE_* env vars
+	state := map[string]string{}
+	ghEnvs := map[string]string{}
+
+	if preEntry != "" {
+		ok, err := core.EvaluateStepCondition(c, u.If(n.actionRuns.PreIf != "", n.actionRuns.PreIf, "always()"), false)
 		if err != nil {
-			return err
+			return nil, nil, core.CreateErr(c, err, "failed to evaluate 'pre-if' of action '%s'", n.actionName)
+		}
+		if ok {
+			utils.LogOut.Infof("Pre Run '%s (%s)'\n", n.GetId(), n.GetNodeTypeId())
+			_, preEnvs, err := n.runStage(c, preEntry, currentEnvMap, state)
+			if err != nil {
+				return nil, nil, err
+			}
+			maps.Copy(currentEnvMap, preEnvs)
+			maps.Copy(ghEnvs, preEnvs)
 		}
 	}
 
-	if runErr != nil {
-		err = n.Execute(ni.Core_gh_action_v1_Output_exec_err, c, runErr)
-		if err != nil {
+	// the post step is queued before the main step runs, so it can clean up if the main step fails
+	if postEntry != "" {
+		postEnvMap := maps.Clone(currentEnvMap)
+		c.AddPostStep(n, n.GetId(), func(pc *core.ExecutionState, runErr error) error {
+			ok, err := core.EvaluateStepCondition(pc, u.If(n.actionRuns.PostIf != "", n.actionRuns.PostIf, "always()"), runErr != nil)
+			if err != nil {
+				return core.CreateErr(pc, err, "failed to evaluate 'post-if' of action '%s'", n.actionName)
+			}
+			if !ok {
+				return nil
+			}
+			utils.LogOut.Infof("Post Run '%s (%s)'\n", n.GetId(), n.GetNodeTypeId())
+			_, _, err = n.runStage(pc, postEntry, postEnvMap, state)
 			return err
-		}
-		return nil
+		})
 	}
 
-	// process fiel commands post-execution (GITHUB_ENV, GITHUB_OUTPUT, GITHUB_PATH)
-	ghEnvs, err = ghContextParser.Parse(c, currentEnvMap)
+	outputs, mainEnvs, err := n.runStage(c, mainEntry, currentEnvMap, state)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	maps.Copy(ghEnvs, mainEnvs)
+	return outputs, ghEnvs, nil
+}
 
-	// transfer env vars to next node
-	nextEnvMap := c.GetContextEnvironMapCopy()
-	maps.Copy(nextEnvMap, ghEnvs)
-	c.SetContextEnvironMap(nextEnvMap)
+// runStage runs the pre, main or post step of a JavaScript or Docker action. The entry is
+// the script of a JavaScript action, or the entrypoint of a Docker action. Empty means the
+// entrypoint of the image.
+func (n *GhActionNode) runStage(c *core.ExecutionState, entry string, envMap map[string]string, state map[string]string) (map[string]string, map[string]string, error) {
+	stageEnvMap := maps.Clone(envMap)
+	for name, value := range state {
+		stageEnvMap["STATE_"+name] = value
+	}
 
-	// parse GITHUB_OUTPUT
-	githubOutput := currentEnvMap["GITHUB_OUTPUT"]
-	if githubOutput != "" {
-		b, err := os.ReadFile(githubOutput)
-		if err != nil {
-			return core.CreateErr(c, err, "unable to read github output file")
-		}
+	// init context parser for file commands (GITHUB_PATH, GITHUB_ENV, GITHUB_STATE)
+	ghContextParser := GhContextParser{}
+	fileCommandEnvs, err := ghContextParser.Init(c, stageEnvMap["RUNNER_TEMP"])
+	if err != nil {
+		return nil, nil, err
+	}
+	maps.Copy(stageEnvMap, fileCommandEnvs)
 
-		outputs, err := parseOutputFile(string(b))
-		if err != nil {
-			return err
-		}
-		for key, value := range outputs {
-			err = n.SetOutputValue(c, core.OutputId(key), strings.TrimRight(value, "\t\n"), core.SetOutputValueOpts{
-				NotExistsIsNoError: true,
-			})
-			if err != nil {
-				return err
-			}
-		}
+	executionEnv := maps.Clone(stageEnvMap)
+
+	// the deprecated `set-output` and `save-state` commands write to the file commands
+	stdout := core.NewWorkflowCommandWriter(c, utils.LogOut.Out, stageEnvMap)
+	stderr := utils.NewLineWriter(utils.LogErr.Out)
 
-		_ = os.Remove(githubOutput)
+	var runErr error
+	switch n.actionType {
+	case Docker:
+		runErr = n.ExecuteDocker(c, stageEnvMap["GITHUB_WORKSPACE"], executionEnv, entry, stdout, stderr)
+	case Node:
+		runErr = n.ExecuteNode(c, stageEnvMap["GITHUB_WORKSPACE"], executionEnv, entry, stdout, stderr)
 	}
 
-	err = n.Execute(ni.Core_gh_action_v1_Output_exec_success, c, nil)
+	err = stdout.Close()
+	if err != nil && runErr == nil {
+		runErr = err
+	}
+	err = stderr.Close()
+	if err != nil && runErr == nil {
+		runErr = err
+	}
+
+	// the state is kept even if the step failed, a post step might need it to clean up
+	savedState, err := ghContextParser.ParseState(c, stageEnvMap)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	maps.Copy(state, savedState)
 
-	return nil
+	if runErr != nil {
+		return nil, nil, runErr
+	}
+
+	// process fiel commands post-execution (GITHUB_ENV, GITHUB_OUTPUT, GITHUB_PATH)
+	ghEnvs, err := ghContextParser.Parse(c, stageEnvMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputs, err := readGithubOutput(c, stageEnvMap["GITHUB_OUTPUT"])
+	if err != nil {
+		return nil, nil, err
+	}
+	return outputs, ghEnvs, nil
+}
+
+// readGithubOutput reads the outputs written to the GITHUB_OUTPUT file and removes the file.
+func readGithubOutput(c *core.ExecutionState, githubOutput string) (map[string]string, error) {
+	outputs := map[string]string{}
+	if githubOutput == "" {
+		return outputs, nil
+	}
+
+	b, err := os.ReadFile(githubOutput)
+	if err != nil {
+		return nil, core.CreateErr(c, err, "unable to read github output file")
+	}
+
+	values, err := parseOutputFile(string(b))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		outputs[key] = strings.TrimRight(value, "\t\n")
+	}
+
+	_ = os.Remove(githubOutput)
+	return outputs, nil
 }
 
-func (n *GhActionNode) ExecuteNode(c *core.ExecutionState, workspace string, envs map[string]string) error {
+func (n *GhActionNode) ExecuteNode(c *core.ExecutionState, workspace string, envs map[string]string, scriptPath string, stdout io.Writer, stderr io.Writer) error {
 	nodeBin := "node"
 	runners, err := getRunnersDir()
 	if err == nil {
@@ -244,12 +377,13 @@ func (n *GhActionNode) ExecuteNode(c *core.ExecutionState, workspace string, env
 		}
 	}
 
-	utils.LogOut.Infof("Use node binary: %s %s\n", nodeBin, n.actionRunJsPath)
+	utils.LogOut.Infof("Use node binary: %s %s\n", nodeBin, scriptPath)
 
-	cmd := exec.Command(nodeBin, n.actionRunJsPath)
+	cmd := exec.CommandContext(c.Ctx, nodeBin, scriptPath)
+	utils.SetupProcessGroup(cmd)
 	cmd.Dir = workspace
-	cmd.Stdout = utils.LogOut.Out
-	cmd.Stderr = utils.LogErr.Out
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	cmd.Stdin = nil
 	cmd.Env = func() []string {
 		env := make([]string, 0)
@@ -258,14 +392,14 @@ func (n *GhActionNode) ExecuteNode(c *core.ExecutionState, workspace string, env
 		}
 		return env
 	}()
-	err = cmd.Run()
+	err = utils.RunProcess(cmd)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (n *GhActionNode) ExecuteDocker(c *core.ExecutionState, workingDirectory string, env map[string]string) error {
+func (n *GhActionNode) ExecuteDocker(c *core.ExecutionState, workingDirectory string, env map[string]string, entrypoint string, stdout io.Writer, stderr io.Writer) error {
 	// Replicating logic from ContainerActionHandler.cs
 	sysRunnerTempDir := env["RUNNER_TEMP"]
 	if sysRunnerTempDir == "" {
@@ -305,6 +439,7 @@ func (n *GhActionNode) ExecuteDocker(c *core.ExecutionState, workingDirectory st
 		ContainerImage:                n.Data.Image,
 		ContainerDisplayName:          fmt.Sprintf("actionforge_%s_%s", n.Data.DockerInstanceLabel, uuid.New()),
 		ContainerWorkDirectory:        dockerGithubWorkspace, // As set in ContainerActionHandler.cs
+		ContainerEntryPoint:           entrypoint,
 		ContainerEntryPointArgs:       strings.Join(ContainerEntryArgs, " "),
 		ContainerEnvironmentVariables: env,
 	}
@@ -346,7 +481,7 @@ func (n *GhActionNode) ExecuteDocker(c *core.ExecutionState, workingDirectory st
 		ReadOnly:         false,
 	})
 
-	exitCode, err := core.DockerRun(context.Background(), n.Data.DockerInstanceLabel, ci, workingDirectory, nil, nil)
+	exitCode, err := core.DockerRun(c.Ctx, n.Data.DockerInstanceLabel, ci, workingDirectory, stdout, stderr)
 	if err != nil {
 		return err
 	}
@@ -450,6 +585,12 @@ func init() {
 		node := &GhActionNode{
 			actionName: action.Name,
 			actionRuns: action.Runs,
+			actionDir:  actionDir,
+		}
+
+		// actions used by the steps of a composite action belong to it
+		if composite, ok := parent.(*GhActionNode); ok {
+			node.SetParent(composite)
 		}
 
 		switch action.Runs.Using {
@@ -532,11 +673,33 @@ func init() {
 
 			node.actionRunJsPath = actionRunFile
 
+			for _, stage := range []struct {
+				file string
+				path *string
+			}{
+				{action.Runs.Pre, &node.actionRunPreJsPath},
+				{action.Runs.Post, &node.actionRunPostJsPath},
+			} {
+				if stage.file == "" {
+					continue
+				}
+				stageRunFile := filepath.Clean(filepath.Join(actionDir, stage.file))
+				_, err := os.Stat(stageRunFile)
+				if errors.Is(err, os.ErrNotExist) {
+					return nil, []error{core.CreateErr(nil, nil, "action run file does not exist: %s", stageRunFile)}
+				}
+				*stage.path = stageRunFile
+			}
+
 		case "composite":
-			// we should never see a composite here, since they should have already been expanded by the editor/gateway
-			// into a group node. In the future we may add support by returning the group node here in case graph
-			// files were created without the editor/gateway.
-			fallthrough
+			node.actionType = Composite
+			node.compositeOutputs = action.Outputs
+
+			steps, errs := loadCompositeSteps(node, parentId, action.Runs.Steps, validate)
+			if len(errs) > 0 {
+				return nil, errs
+			}
+			node.compositeSteps = steps
 		default:
 			return nil, []error{core.CreateErr(nil, nil, "unsupported action run type: %s", action.Runs.Using)}
 		}
@@ -631,14 +794,38 @@ type ActionInput struct {
 
 type ActionOutput struct {
 	Description string `json:"description"`
+	// Value is the expression of an output of a composite action
+	Value string `json:"value"`
 }
 
 type ActionRuns struct {
-	Image string   `json:"image"`
-	Using string   `json:"using"`
-	Main  string   `json:"main"`
-	Post  string   `json:"post"`
-	Args  []string `json:"args"`
+	Image string       `json:"image"`
+	Using string       `json:"using"`
+	Main  string       `json:"main"`
+	Pre   string       `json:"pre"`
+	Post  string       `json:"post"`
+	Args  []string     `json:"args"`
+	Steps []ActionStep `json:"steps"`
+
+	PreIf          string `yaml:"pre-if"`
+	PostIf         string `yaml:"post-if"`
+	Entrypoint     string `yaml:"entrypoint"`
+	PreEntrypoint  string `yaml:"pre-entrypoint"`
+	PostEntrypoint string `yaml:"post-entrypoint"`
+}
+
+// ActionStep is a step of a composite action.
+type ActionStep struct {
+	Id               string            `yaml:"id"`
+	Name             string            `yaml:"name"`
+	If               string            `yaml:"if"`
+	Run              string            `yaml:"run"`
+	Shell            string            `yaml:"shell"`
+	Uses             string            `yaml:"uses"`
+	With             map[string]string `yaml:"with"`
+	Env              map[string]string `yaml:"env"`
+	WorkingDirectory string            `yaml:"working-directory"`
+	ContinueOnError  string            `yaml:"continue-on-error"`
 }
 
 // getRunnersDir returns the directory of the latest runner version.
@@ -0,0 +1,292 @@
+package nodes
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/actionforge/actrun-cli/core"
+	"github.com/actionforge/actrun-cli/utils"
+)
+
+// compositeStep is a step of a composite action. The action of a step
+// with `uses` is loaded together with the composite action.
+type compositeStep struct {
+	ActionStep
+	action *GhActionNode
+}
+
+func loadCompositeSteps(node *GhActionNode, parentId string, actionSteps []ActionStep, validate bool) ([]compositeStep, []error) {
+	if len(actionSteps) == 0 {
+		return nil, []error{core.CreateErr(nil, nil, "composite action '%s' has no steps", node.actionName)}
+	}
+
+	var (
+		steps []compositeStep
+		errs  []error
+	)
+
+	stepIds := map[string]bool{}
+	for i, actionStep := range actionSteps {
+		step := compositeStep{ActionStep: actionStep}
+		if step.Id == "" {
+			// steps without id can't be referenced by other steps
+			step.Id = fmt.Sprintf("__step_%d", i)
+		} else if stepIds[step.Id] {
+			errs = append(errs, core.CreateErr(nil, nil, "step id '%s' is used more than once in composite action '%s'", step.Id, node.actionName))
+			continue
+		}
+		stepIds[step.Id] = true
+
+		switch {
+		case step.Run != "" && step.Uses != "":
+			errs = append(errs, core.CreateErr(nil, nil, "step '%s' of composite action '%s' has both 'run' and 'uses'", step.Id, node.actionName))
+			continue
+		case step.Run != "":
+			if step.Shell == "" {
+				step.Shell = utils.If(runtime.GOOS == "windows", "pwsh", "bash")
+			}
+			if !slices.Contains([]string{"bash", "pwsh", "python", "cmd"}, step.Shell) {
+				errs = append(errs, core.CreateErr(nil, nil, "unsupported shell '%s' in step '%s' of composite action '%s'", step.Shell, step.Id, node.actionName).
+					SetHint("the supported shells are bash, pwsh, python and cmd"))
+				continue
+			}
+		case step.Uses != "":
+			if strings.HasPrefix(step.Uses, "./") || strings.HasPrefix(step.Uses, "docker://") {
+				errs = append(errs, core.CreateErr(nil, nil, "unsupported action '%s' in step '%s' of composite action '%s'", step.Uses, step.Id, node.actionName).
+					SetHint("steps of composite actions can only use actions of repositories, like 'owner/repo@ref'"))
+				continue
+			}
+
+			action, err := loadCompositeStepAction(node, parentId, step, validate)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			step.action = action
+		default:
+			errs = append(errs, core.CreateErr(nil, nil, "step '%s' of composite action '%s' has neither 'run' nor 'uses'", step.Id, node.actionName))
+			continue
+		}
+		steps = append(steps, step)
+	}
+	return steps, errs
+}
+
+func loadCompositeStepAction(node *GhActionNode, parentId string, step compositeStep, validate bool) (*GhActionNode, error) {
+	nodeType := "github.com/" + step.Uses
+	_, owner, repo, path, ref, err := parseNodeTypeId(nodeType)
+	if err != nil {
+		return nil, core.CreateErr(nil, err, "invalid action '%s' in step '%s' of composite action '%s'", step.Uses, step.Id, node.actionName)
+	}
+
+	// an action that uses itself, directly or through other actions, would never finish
+	home, err := os.UserHomeDir()
+	if err == nil {
+		actionDir := filepath.Join(home, "work", "_actions", owner, repo, ref, path)
+		for p := node; p != nil; p, _ = p.GetParent().(*GhActionNode) {
+			if p.actionDir == actionDir {
+				return nil, core.CreateErr(nil, nil, "composite action '%s' uses itself in step '%s'", node.actionName, step.Id)
+			}
+		}
+	}
+
+	action, errs := core.NewGhActionNode(nodeType, node, parentId+"/"+step.Id, validate)
+	if len(errs) > 0 {
+		return nil, core.CreateErr(nil, errs[0], "failed to load action '%s' of step '%s' in composite action '%s'", step.Uses, step.Id, node.actionName)
+	}
+
+	ghAction := action.(*GhActionNode)
+	ghAction.SetId(step.Id)
+	ghAction.SetFullPath(parentId + "/" + step.Id)
+	return ghAction, nil
+}
+
+// runComposite runs the steps of a composite action one after another. The expressions
+// of the steps have access to the inputs of the action, the outputs of the previous
+// steps and the env vars. After a step failed, only steps with a status function like
+// `failure()` or `always()` in their condition run, and the action fails.
+func (n *GhActionNode) runComposite(c *core.ExecutionState, withInputs map[string]string, currentEnvMap map[string]string) (map[string]string, map[string]string, error) {
+	inputs := map[string]any{}
+	for inputName, v := range withInputs {
+		inputs[inputName] = v
+	}
+
+	steps := map[string]any{}
+	ghEnvs := map[string]string{}
+
+	// all steps of the action run in one execution state, which is cancelled when the action finishes
+	ec := c.PushNewExecutionState(n)
+	defer ec.CtxCancel()
+	ec.Inputs = inputs
+	ec.DataOutputCache = steps
+
+	var failErr error
+	for _, step := range n.compositeSteps {
+		if c.IsCancelled() {
+			return nil, nil, core.CreateErr(c, nil, "composite action '%s' was cancelled", n.actionName)
+		}
+
+		// each step starts with the env vars of the action and the ones set by the previous steps
+		ec.SetContextEnvironMap(maps.Clone(currentEnvMap))
+
+		ok, err := core.EvaluateStepCondition(ec, step.If, failErr != nil)
+		if err != nil {
+			return nil, nil, core.CreateErr(c, err, "failed to evaluate the condition of step '%s'", step.Id)
+		}
+		if !ok {
+			steps[step.Id] = map[string]any{
+				"outputs":    map[string]any{},
+				"outcome":    "skipped",
+				"conclusion": "skipped",
+			}
+			continue
+		}
+
+		outputs, stepEnvs, stepErr := n.runCompositeStep(ec, step)
+
+		outcome := "success"
+		if stepErr != nil {
+			outcome = "failure"
+			utils.LogErr.Errorf("step '%s' of composite action '%s' failed\n", step.Id, n.actionName)
+		}
+		conclusion := outcome
+
+		if stepErr != nil {
+			continueOnError, err := evaluateToString(ec, step.ContinueOnError)
+			if err != nil {
+				return nil, nil, err
+			}
+			if continueOnError == "true" {
+				conclusion = "success"
+			} else if failErr == nil {
+				failErr = core.CreateErr(c, stepErr, "step '%s' of composite action '%s' failed", step.Id, n.actionName)
+			}
+		}
+
+		stepOutputs := map[string]any{}
+		for key, value := range outputs {
+			stepOutputs[key] = value
+		}
+		steps[step.Id] = map[string]any{
+			"outputs":    stepOutputs,
+			"outcome":    outcome,
+			"conclusion": conclusion,
+		}
+
+		// env vars set by a step are visible to the following steps
+		maps.Copy(currentEnvMap, stepEnvs)
+		maps.Copy(ghEnvs, stepEnvs)
+	}
+
+	if failErr != nil {
+		return nil, nil, failErr
+	}
+
+	ec.SetContextEnvironMap(maps.Clone(currentEnvMap))
+	outputs := map[string]string{}
+	for outputName, output := range n.compositeOutputs {
+		v, err := evaluateToString(ec, output.Value)
+		if err != nil {
+			return nil, nil, core.CreateErr(c, err, "failed to evaluate output '%s' of composite action '%s'", outputName, n.actionName)
+		}
+		outputs[outputName] = v
+	}
+	return outputs, ghEnvs, nil
+}
+
+func (n *GhActionNode) runCompositeStep(c *core.ExecutionState, step compositeStep) (map[string]string, map[string]string, error) {
+	// the env vars of a step are available in its expressions
+	for _, envName := range slices.Sorted(maps.Keys(step.Env)) {
+		v, err := evaluateToString(c, step.Env[envName])
+		if err != nil {
+			return nil, nil, err
+		}
+		c.Env[envName] = v
+	}
+
+	if step.action != nil {
+		withInputs := map[string]string{}
+		for inputName, value := range step.With {
+			v, err := evaluateToString(c, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			withInputs[inputName] = v
+		}
+
+		for inputName, inputDef := range step.action.GetInputDefs() {
+			if inputDef.Exec || inputDef.Type != "string" {
+				continue
+			}
+			if _, ok := withInputs[string(inputName)]; ok {
+				continue
+			}
+			defaultValue, _ := inputDef.Default.(string)
+			v, err := evaluateToString(c, defaultValue)
+			if err != nil {
+				return nil, nil, err
+			}
+			withInputs[string(inputName)] = v
+		}
+
+		return step.action.runAction(c, withInputs, maps.Clone(c.Env))
+	}
+
+	script, err := evaluateToString(c, step.Run)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := maps.Clone(c.Env)
+	env["GITHUB_ACTION_PATH"] = n.actionDir
+
+	workingDir, err := evaluateToString(c, step.WorkingDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !filepath.IsAbs(workingDir) {
+		workingDir = filepath.Join(env["GITHUB_WORKSPACE"], workingDir)
+	}
+
+	ghContextParser := GhContextParser{}
+	fileCommandEnvs, err := ghContextParser.Init(c, env["RUNNER_TEMP"])
+	if err != nil {
+		return nil, nil, err
+	}
+	maps.Copy(env, fileCommandEnvs)
+
+	utils.LogOut.Infof("Run %s\n", utils.If(step.Name != "", step.Name, strings.TrimSpace(script)))
+
+	_, _, err = runCommand(c, step.Shell, &script, nil, "both", nil, env, workingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ghEnvs, err := ghContextParser.Parse(c, env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputs, err := readGithubOutput(c, env["GITHUB_OUTPUT"])
+	if err != nil {
+		return nil, nil, err
+	}
+	return outputs, ghEnvs, nil
+}
+
+// evaluateToString evaluates the expressions in a value of a composite action.
+func evaluateToString(c *core.ExecutionState, value string) (string, error) {
+	v, err := core.NewEvaluator(c).Evaluate(value)
+	if err != nil {
+		return "", core.CreateErr(c, err, "failed to evaluate '%s'", value)
+	}
+	if v == nil {
+		return "", nil
+	}
+	return fmt.Sprint(v), nil
+}
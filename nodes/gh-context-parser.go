@@ -95,6 +95,37 @@ func (p *GhContextParser) Parse(c *core.ExecutionState, contextEnvironMap map[st
 	return envs, nil
 }
 
+// ParseState reads the state a step saved with GITHUB_STATE. The following steps
+// of the same action get it as STATE_* env vars.
+func (p *GhContextParser) ParseState(c *core.ExecutionState, contextEnvironMap map[string]string) (map[string]string, error) {
+	state := map[string]string{}
+
+	githubState := contextEnvironMap["GITHUB_STATE"]
+	if githubState == "" {
+		return state, nil
+	}
+
+	b, err := os.ReadFile(githubState)
+	if err != nil {
+		return nil, core.CreateErr(c, err, "unable to read file set in GITHUB_STATE")
+	}
+	values, err := parseOutputFile(string(b))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range values {
+		state[name] = strings.TrimRight(value, " \t\n\r")
+	}
+
+	err = os.Remove(githubState)
+	if err != nil {
+		return nil, core.CreateErr(c, err, "unable to remove file set in GITHUB_STATE")
+	}
+
+	delete(contextEnvironMap, "GITHUB_STATE")
+	return state, nil
+}
+
 func parseOutputFile(input string) (map[string]string, error) {
 	results := make(map[string]string)
 	lines := strings.Split(input, "\n")
@@ -35,6 +35,10 @@ func (n *GroupInputsNode) OutputValueById(c *core.ExecutionState, outputId core.
 	return v, nil
 }
 
+func (n *GroupInputsNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeForward
+}
+
 func (n *GroupInputsNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
 	err := n.Execute(core.OutputId(inputId), c, nil)
 	if err != nil {
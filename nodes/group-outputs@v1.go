@@ -25,6 +25,10 @@ func (n *GroupOutputsNode) OutputValueById(c *core.ExecutionState, outputId core
 	return v, nil
 }
 
+func (n *GroupOutputsNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeForward
+}
+
 func (n *GroupOutputsNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
 	// Forward errors from a previous node to the group node
 	err := n.Execute(core.OutputId(inputId), c, prevError)
@@ -2,6 +2,8 @@ package nodes
 
 import (
 	_ "embed"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/actionforge/actrun-cli/core"
@@ -17,9 +19,27 @@ type GroupNode struct {
 	core.Inputs
 	core.Outputs
 	core.Executions
+
+	graphRef *core.GraphRef
+
+	// If true, the group runs in its own execution state, so changes of the environment
+	// and output values inside the group don't affect the nodes outside of it.
+	isolated bool
+}
+
+func (n *GroupNode) GetGraphRef() *core.GraphRef {
+	return n.graphRef
 }
 
 func (n *GroupNode) OutputValueById(c *core.ExecutionState, outputId core.OutputId) (any, error) {
+	// The outputs of an isolated group are evaluated in the execution state of the group,
+	// where the nodes inside the group cached their outputs.
+	if n.isolated {
+		if inner := c.GroupState(n); inner != nil {
+			c = inner
+		}
+	}
+
 	v, err := n.InputValueById(c, n, core.InputId(outputId), nil)
 	if err != nil {
 		return nil, err
@@ -28,48 +48,61 @@ func (n *GroupNode) OutputValueById(c *core.ExecutionState, outputId core.Output
 	return v, nil
 }
 
+// Group nodes only forward the execution into and out of the group,
+// so they always run when a run is resumed.
+func (n *GroupNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeForward
+}
+
 func (n *GroupNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
 	// For group nodes, `ExecuteImpl` is called twice. First when entered, then the second time from the node that leaves the group (mostly group-outputs@v1)
 
-	// TODO(Seb): Evaluate if group nodes should create their own execution state.
-	// Currently, I use the shared parent state to preserve visited nodes and output values.
-	// To support this in the future, the inner graph execution would need to be merged into the outer one
-	// when the group node is left. Review if this comes with other advantages
-	// Draft implementation for push/pull execution state is commented out below.
-	/*
-		_, ok := n.Inputs.GetInputDefs()[inputId]
-		if ok {
-			// entering the group node
-			c = c.PushNewExecutionState(n)
-		} else {
-			_, _, ok := n.Outputs.OutputDefByPortId(string(inputId))
-			if !ok {
-				return core.CreateErr(nil, nil, "group node '%s' has no input or output with id '%s'", n.GetId(), inputId).SetHint(core.HINT_INTERNAL_ERROR)
-			}
-
-			// leaving the group node, pop execution state
-			tmp := c.ParentExecution
-			if tmp == nil {
-				return core.CreateErr(nil, nil, "group node '%s' has no parent execution state", n.GetId()).SetHint(core.HINT_INTERNAL_ERROR)
-			}
-			c = tmp
-		}
-	*/
+	// By default, a group shares the execution state of its caller to preserve visited nodes and output values.
+	// An isolated group gets its own execution state when entered, which is discarded when the group is left.
+	// Only the values of the group outputs are still available to the nodes after the group.
 
 	_, ok := n.Inputs.GetInputDefs()[inputId]
 	if ok {
 		// entering the group node
-		c.Hierarchy = append(c.Hierarchy, n)
+		if n.isolated {
+			// The group is left from within this call, so everything after the
+			// group has finished too when it returns.
+			inner := c.PushNewExecutionState(n)
+			defer inner.CtxCancel()
+			c = inner
+		} else {
+			c.Hierarchy = append(c.Hierarchy, n)
+		}
 	} else {
 		_, _, ok := n.Outputs.OutputDefByPortId(string(inputId))
 		if !ok {
 			return core.CreateErr(nil, nil, "group node '%s' has no input or output with id '%s'", n.GetId(), inputId).SetHint(core.HINT_INTERNAL_ERROR)
 		}
 
-		if len(c.Hierarchy) == 0 {
-			return core.CreateErr(nil, nil, "group node '%s' has no parent execution state", n.GetId()).SetHint(core.HINT_INTERNAL_ERROR)
+		if n.isolated {
+			// leaving the group node, continue in the execution state the group was entered from
+			groupState := c
+			for groupState != nil && groupState.CreatedBy != n {
+				groupState = groupState.ParentExecution
+			}
+			if groupState == nil || groupState.ParentExecution == nil {
+				return core.CreateErr(nil, nil, "group node '%s' has no parent execution state", n.GetId()).SetHint(core.HINT_INTERNAL_ERROR)
+			}
+
+			groupState.ParentExecution.SetGroupState(n, c)
+			c = groupState.ParentExecution
+		} else {
+			// The group is removed by identity. It may be left from within the body of
+			// a loop that was entered after the group, whose entry has to stay.
+			i := len(c.Hierarchy) - 1
+			for i >= 0 && c.Hierarchy[i] != core.NodeBaseInterface(n) {
+				i--
+			}
+			if i < 0 {
+				return core.CreateErr(nil, nil, "group node '%s' has no parent execution state", n.GetId()).SetHint(core.HINT_INTERNAL_ERROR)
+			}
+			c.Hierarchy = slices.Delete(slices.Clone(c.Hierarchy), i, i+1)
 		}
-		c.Hierarchy = c.Hierarchy[:len(c.Hierarchy)-1]
 	}
 
 	err := n.Execute(core.OutputId(inputId), c, prevError)
@@ -84,14 +117,51 @@ func init() {
 	err := core.RegisterNodeFactory(subgraphDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
 		var collectedErrors []error
 
-		subGraph, ok := nodeDef["graph"].(map[string]any)
-		if !ok {
-			err := core.CreateErr(nil, nil, "group node '%s' (%s) has an invalid graph definition", nodeDef["id"], nodeDef["type"])
-			// Critical error, cannot proceed without graph definition
-			return nil, []error{err}
+		group := &GroupNode{}
+
+		// The parent is needed before the inner graph is loaded to detect graphs that reference themselves.
+		group.SetParent(parent)
+
+		// Relative references are resolved against the directory of the graph file the group is defined in.
+		var lc core.GraphLoadContext
+		if c, ok := ctx.(*core.GraphLoadContext); ok && c != nil {
+			lc = *c
 		}
 
-		group := &GroupNode{}
+		if isolated, ok := nodeDef["isolated"]; ok {
+			group.isolated, ok = isolated.(bool)
+			if !ok {
+				return nil, []error{core.CreateErr(nil, nil, "group node '%s' (%s) has an invalid value for 'isolated'", nodeDef["id"], nodeDef["type"]).
+					SetHint("use 'isolated: true' or 'isolated: false'")}
+			}
+		}
+
+		var subGraph map[string]any
+		if ref, ok := nodeDef["ref"]; ok {
+			refStr, ok := ref.(string)
+			if !ok || refStr == "" {
+				return nil, []error{core.CreateErr(nil, nil, "group node '%s' (%s) has an invalid ref", nodeDef["id"], nodeDef["type"])}
+			}
+			if _, ok := nodeDef["graph"]; ok {
+				return nil, []error{core.CreateErr(nil, nil, "group node '%s' (%s) has both a graph and a ref", nodeDef["id"], nodeDef["type"]).
+					SetHint("remove either the inline graph or the ref")}
+			}
+
+			var err error
+			subGraph, group.graphRef, err = core.LoadGraphRef(refStr, parent, lc)
+			if err != nil {
+				// Critical error, cannot proceed without graph definition
+				return nil, []error{err}
+			}
+		} else {
+			var ok bool
+			subGraph, ok = nodeDef["graph"].(map[string]any)
+			if !ok {
+				err := core.CreateErr(nil, nil, "group node '%s' (%s) has an invalid graph definition", nodeDef["id"], nodeDef["type"])
+				// Critical error, cannot proceed without graph definition
+				return nil, []error{err}
+			}
+		}
 
 		graphOutputs, err := core.LoadGraphOutputs(subGraph)
 		if err != nil {
@@ -143,9 +213,18 @@ func init() {
 			}
 		}
 
+		if group.graphRef != nil {
+			lc.Dir = filepath.Dir(group.graphRef.Path)
+		}
+
 		// Pass 'validate' to LoadGraph to collect internal graph errors
-		ag, errs := core.LoadGraph(subGraph, group, parentId, validate)
+		ag, errs := core.LoadGraph(subGraph, group, parentId, lc, validate)
 		if len(errs) > 0 {
+			if group.graphRef != nil {
+				for i, err := range errs {
+					errs[i] = core.CreateErr(nil, err, "failed to load referenced graph '%s'", group.graphRef.Ref)
+				}
+			}
 			if !validate {
 				return nil, errs
 			}
@@ -206,6 +285,7 @@ func init() {
 			var groupOutputNode *GroupOutputsNode
 			for _, node := range ag.GetNodes() {
 				if strings.HasPrefix(node.GetNodeTypeId(), "core/group-outputs@") {
+					var ok bool
 					groupOutputNode, ok = node.(*GroupOutputsNode)
 					if !ok {
 						err := core.CreateErr(nil, nil, "group output node is not a group output node")
@@ -34,7 +34,7 @@ var allowedMethods = map[string]struct{}{
 }
 
 func buildRequest(c *core.ExecutionState, method, url string, headers []string, reader io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, reader)
+	req, err := http.NewRequestWithContext(c.Ctx, method, url, reader)
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +101,6 @@ func (n *HttpNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, pre
 	}
 
 	resp, connErr := client.Do(req)
-	if resp != nil {
-		defer resp.Body.Close()
-	}
 
 	// Ensure the input reader is closed in all cases.
 	// If closing the reader fails without a prior error,
@@ -122,8 +119,10 @@ func (n *HttpNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, pre
 		statusCode = resp.StatusCode
 	}
 
+	// the executor closes the body once no node can read it anymore
 	err = n.Outputs.SetOutputValue(c, ni.Core_http_v1_Output_body, dsf, core.SetOutputValueOpts{})
 	if err != nil {
+		dsf.CloseStreamAndIgnoreError()
 		return err
 	}
 
@@ -0,0 +1,37 @@
+package nodes
+
+import (
+	_ "embed"
+
+	"github.com/actionforge/actrun-cli/core"
+	ni "github.com/actionforge/actrun-cli/node_interfaces"
+)
+
+//go:embed loop-break@v1.yml
+var loopBreakDefinition string
+
+type LoopBreakNode struct {
+	core.NodeBaseComponent
+	core.Inputs
+	core.Executions
+}
+
+func (n *LoopBreakNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
+	loopId, err := core.InputValueById[string](c, n, ni.Core_loop_break_v1_Input_loop)
+	if err != nil {
+		return err
+	}
+
+	// The signal is returned like an error, so the nodes of the loop body
+	// return one after another until the loop receives it.
+	return core.NewLoopSignal(c, n, loopId, true)
+}
+
+func init() {
+	err := core.RegisterNodeFactory(loopBreakDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
+		return &LoopBreakNode{}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
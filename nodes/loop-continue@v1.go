@@ -0,0 +1,37 @@
+package nodes
+
+import (
+	_ "embed"
+
+	"github.com/actionforge/actrun-cli/core"
+	ni "github.com/actionforge/actrun-cli/node_interfaces"
+)
+
+//go:embed loop-continue@v1.yml
+var loopContinueDefinition string
+
+type LoopContinueNode struct {
+	core.NodeBaseComponent
+	core.Inputs
+	core.Executions
+}
+
+func (n *LoopContinueNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
+	loopId, err := core.InputValueById[string](c, n, ni.Core_loop_continue_v1_Input_loop)
+	if err != nil {
+		return err
+	}
+
+	// The rest of the iteration is skipped as the signal
+	// travels up the loop body like an error.
+	return core.NewLoopSignal(c, n, loopId, false)
+}
+
+func init() {
+	err := core.RegisterNodeFactory(loopContinueDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
+		return &LoopContinueNode{}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
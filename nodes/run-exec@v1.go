@@ -78,7 +78,7 @@ func (n *RunExecNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId,
 		}
 	}
 
-	output, exitCode, runErr := runCommand(c, path, nil, args, print, stdin, currentEnvMap)
+	output, exitCode, runErr := runCommand(c, path, nil, args, print, stdin, currentEnvMap, "")
 
 	// I don't see a reason here why capturing a
 	// failed reader close would be important here
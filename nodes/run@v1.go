@@ -109,7 +109,7 @@ func (n *RunNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prev
 		}
 	}
 
-	output, exitCode, runErr := runCommand(c, shell, &script, args, print, stdin, currentEnvMap)
+	output, exitCode, runErr := runCommand(c, shell, &script, args, print, stdin, currentEnvMap, "")
 
 	// I don't see a reason here why capturing a
 	// failed reader close would be important here
@@ -152,7 +152,7 @@ func (n *RunNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prev
 	return nil
 }
 
-func runCommand(c *core.ExecutionState, shell string, script *string, args []string, print string, stdin io.Reader, curEnvMap map[string]string) (string, int, error) {
+func runCommand(c *core.ExecutionState, shell string, script *string, args []string, print string, stdin io.Reader, curEnvMap map[string]string, workingDir string) (string, int, error) {
 	for i, arg := range args {
 		args[i] = strings.TrimSpace(arg)
 	}
@@ -163,7 +163,7 @@ func runCommand(c *core.ExecutionState, shell string, script *string, args []str
 	)
 
 	if script == nil {
-		cmd = exec.Command(shell, args...)
+		cmd = exec.CommandContext(c.Ctx, shell, args...)
 	} else {
 		scriptName := "run-script-*"
 		if runtime.GOOS == "windows" {
@@ -241,10 +241,13 @@ func runCommand(c *core.ExecutionState, shell string, script *string, args []str
 
 			args = append([]string{scriptPath}, args...)
 		}
-		cmd = exec.Command(shell, args...)
+		cmd = exec.CommandContext(c.Ctx, shell, args...)
 
 	}
 
+	utils.SetupProcessGroup(cmd)
+
+	cmd.Dir = workingDir
 	if stdin != nil {
 		cmd.Stdin = stdin
 	}
@@ -257,7 +260,7 @@ func runCommand(c *core.ExecutionState, shell string, script *string, args []str
 	}()
 
 	var combinedOutput bytes.Buffer
-	runErr := runAndCaptureOutput(c, cmd, print, &combinedOutput)
+	runErr := runAndCaptureOutput(c, cmd, print, &combinedOutput, curEnvMap)
 
 	combinedOutputStr, err := utils.DecodeBytes(combinedOutput.Bytes())
 	if err != nil {
@@ -359,18 +362,26 @@ func determineBashPath() (string, string, bool) {
 	}
 }
 
-func runAndCaptureOutput(c *core.ExecutionState, cmd *exec.Cmd, print string, combinedOutput *bytes.Buffer) error {
+func runAndCaptureOutput(c *core.ExecutionState, cmd *exec.Cmd, print string, combinedOutput *bytes.Buffer, envMap map[string]string) error {
 	var runErr error
 
+	// workflow commands like `::add-mask::` are processed even if stdout isn't printed
+	stdoutLog := utils.LogOut.Out
+	if print == "output" {
+		stdoutLog = io.Discard
+	}
+	workflowCommands := core.NewWorkflowCommandWriter(c, stdoutLog, envMap)
+	stderrLog := utils.NewLineWriter(utils.LogErr.Out)
+
 	utf8Decoder := unicode.UTF8.NewDecoder()
-	stdoutTransformer := transform.NewWriter(io.MultiWriter(utils.LogOut.Out, combinedOutput), utf8Decoder)
-	stderrTransformer := transform.NewWriter(io.MultiWriter(utils.LogErr.Out, combinedOutput), utf8Decoder)
+	stdoutTransformer := transform.NewWriter(io.MultiWriter(workflowCommands, combinedOutput), utf8Decoder)
+	stderrTransformer := transform.NewWriter(io.MultiWriter(stderrLog, combinedOutput), utf8Decoder)
 	switch print {
 	case "stdout":
 		cmd.Stdout = stdoutTransformer
-		cmd.Stderr = utils.LogErr.Out
+		cmd.Stderr = stderrLog
 	case "output":
-		transformer := transform.NewWriter(combinedOutput, utf8Decoder)
+		transformer := transform.NewWriter(io.MultiWriter(workflowCommands, combinedOutput), utf8Decoder)
 		cmd.Stdout = transformer
 		cmd.Stderr = transformer
 	default: // if 'both'
@@ -378,7 +389,16 @@ func runAndCaptureOutput(c *core.ExecutionState, cmd *exec.Cmd, print string, co
 		cmd.Stderr = stderrTransformer
 	}
 
-	runErr = cmd.Run()
+	runErr = utils.RunProcess(cmd)
+
+	err := workflowCommands.Close()
+	if err != nil && runErr == nil {
+		runErr = err
+	}
+	err = stderrLog.Close()
+	if err != nil && runErr == nil {
+		runErr = err
+	}
 
 	if runErr != nil {
 		return core.CreateErr(c, runErr, "failed to run command")
@@ -0,0 +1,64 @@
+package nodes
+
+import (
+	_ "embed"
+
+	"github.com/actionforge/actrun-cli/core"
+	ni "github.com/actionforge/actrun-cli/node_interfaces"
+)
+
+//go:embed semaphore@v1.yml
+var semaphoreDefinition string
+
+type SemaphoreNode struct {
+	core.NodeBaseComponent
+	core.Inputs
+	core.Outputs
+	core.Executions
+}
+
+// The semaphore is only held while the acquired branch runs,
+// so the node must run again to acquire it for the branch.
+func (n *SemaphoreNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeRestart
+}
+
+func (n *SemaphoreNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
+	name, err := core.InputValueById[string](c, n, ni.Core_semaphore_v1_Input_name)
+	if err != nil {
+		return err
+	}
+
+	count, err := core.InputValueById[int](c, n, ni.Core_semaphore_v1_Input_count)
+	if err != nil {
+		return err
+	}
+
+	release, err := c.AcquireSemaphore(name, int64(count))
+	if err != nil {
+		return err
+	}
+
+	err = n.executeAcquired(c, release)
+	if err != nil {
+		return err
+	}
+
+	return n.Execute(ni.Core_semaphore_v1_Output_exec_released, c, nil)
+}
+
+// executeAcquired runs the acquired branch and releases the semaphore
+// when the branch returns, also if one of its nodes panicked.
+func (n *SemaphoreNode) executeAcquired(c *core.ExecutionState, release func()) error {
+	defer release()
+	return n.Execute(ni.Core_semaphore_v1_Output_exec_acquired, c, nil)
+}
+
+func init() {
+	err := core.RegisterNodeFactory(semaphoreDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
+		return &SemaphoreNode{}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
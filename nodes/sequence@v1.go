@@ -17,6 +17,10 @@ type SequenceNode struct {
 	core.Executions
 }
 
+func (n *SequenceNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeRestart
+}
+
 func (n *SequenceNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
 
 	type execIndexPorts struct {
@@ -353,7 +353,7 @@ executions: []
 		return nil, nil, nil, nil, err
 	}
 
-	ag, errs := core.LoadGraph(graphYaml, nil, "", false)
+	ag, errs := core.LoadGraph(graphYaml, nil, "", core.GraphLoadContext{}, false)
 	if errs != nil {
 		return nil, nil, nil, nil, errs[0]
 	}
@@ -0,0 +1,71 @@
+package nodes
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+
+	"github.com/actionforge/actrun-cli/core"
+	ni "github.com/actionforge/actrun-cli/node_interfaces"
+)
+
+//go:embed try@v1.yml
+var tryDefinition string
+
+type TryNode struct {
+	core.NodeBaseComponent
+	core.Inputs
+	core.Outputs
+	core.Executions
+}
+
+// The node hands over to its try branch first, so the catch
+// and finally branches would be lost if the node was skipped.
+func (n *TryNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeRestart
+}
+
+func (n *TryNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
+	err := n.Execute(ni.Core_try_v1_Output_exec_try, c, nil)
+
+	_, hasCatch := n.GetExecutionTarget(ni.Core_try_v1_Output_exec_catch)
+	// breaking or continuing a loop around the try node is not an error
+	if err != nil && hasCatch && !c.IsCancelled() && !core.IsLoopSignal(err) {
+		err = n.SetOutputValue(c, ni.Core_try_v1_Output_error, errorMessage(err), core.SetOutputValueOpts{})
+		if err == nil {
+			err = n.Execute(ni.Core_try_v1_Output_exec_catch, c, nil)
+		}
+	}
+
+	// The finally branch must also run if the run was cancelled, so it gets a context
+	// that isn't cancelled with the run. Timeouts of the nodes inside still apply.
+	ctx := c.Ctx
+	c.Ctx = context.WithoutCancel(ctx)
+	finallyErr := n.Execute(ni.Core_try_v1_Output_exec_finally, c, nil)
+	c.Ctx = ctx
+
+	if err != nil && finallyErr != nil {
+		return errors.Join(err, finallyErr)
+	} else if finallyErr != nil {
+		return finallyErr
+	}
+	return err
+}
+
+// errorMessage returns the message of an error including the messages of its causes.
+func errorMessage(err error) string {
+	var leafErr *core.LeafError
+	if errors.As(err, &leafErr) {
+		return leafErr.ErrorWithCauses()
+	}
+	return err.Error()
+}
+
+func init() {
+	err := core.RegisterNodeFactory(tryDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
+		return &TryNode{}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
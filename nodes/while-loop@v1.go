@@ -0,0 +1,96 @@
+package nodes
+
+import (
+	_ "embed"
+
+	"github.com/actionforge/actrun-cli/core"
+	ni "github.com/actionforge/actrun-cli/node_interfaces"
+)
+
+//go:embed while-loop@v1.yml
+var whileLoopDefinition string
+
+type WhileLoopNode struct {
+	core.NodeBaseComponent
+	core.Inputs
+	core.Outputs
+	core.Executions
+}
+
+func (n *WhileLoopNode) GetResumeBehavior() core.ResumeBehavior {
+	return core.ResumeRestart
+}
+
+func (n *WhileLoopNode) ExecuteImpl(c *core.ExecutionState, inputId core.InputId, prevError error) error {
+	doWhile, err := core.InputValueById[bool](c, n, ni.Core_while_loop_v1_Input_do_while)
+	if err != nil {
+		return err
+	}
+
+	maxIterations, err := core.InputValueById[int](c, n, ni.Core_while_loop_v1_Input_max_iterations)
+	if err != nil {
+		return err
+	}
+
+	leaveLoop := c.EnterLoop(n)
+	for i := 0; !c.IsCancelled(); i++ {
+		if !doWhile || i > 0 {
+			ok, err := n.evaluateCondition(c)
+			if err != nil {
+				leaveLoop()
+				return err
+			}
+			if !ok {
+				break
+			}
+		}
+
+		if maxIterations > 0 && i >= maxIterations {
+			leaveLoop()
+			return core.CreateErr(c, nil, "while loop '%s' reached the maximum of %d iterations", n.GetId(), maxIterations).
+				SetHint("check that the condition eventually becomes false, or increase 'Max Iterations'")
+		}
+
+		err = n.Outputs.SetOutputValue(c, ni.Core_while_loop_v1_Output_index, i, core.SetOutputValueOpts{})
+		if err != nil {
+			leaveLoop()
+			return err
+		}
+
+		err = n.Execute(ni.Core_while_loop_v1_Output_exec_body, c, nil)
+		if signal, ok := core.LoopSignalFor(n, err); ok {
+			if signal.Break {
+				break
+			}
+			continue
+		}
+		if err != nil {
+			leaveLoop()
+			return err
+		}
+	}
+	leaveLoop()
+
+	err = n.Execute(ni.Core_while_loop_v1_Output_exec_completed, c, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *WhileLoopNode) evaluateCondition(c *core.ExecutionState) (bool, error) {
+	// data nodes must be evaluated again, their values may have changed during the last iteration
+	c.EmptyDataOutputCache()
+
+	return evaluateExpressionOrCondition(c, n, ni.Core_while_loop_v1_Input_expression, ni.Core_while_loop_v1_Input_condition)
+}
+
+func init() {
+	err := core.RegisterNodeFactory(whileLoopDefinition, func(ctx any, parent core.NodeBaseInterface, parentId string, nodeDef map[string]any, validate bool) (core.NodeBaseInterface, []error) {
+		return &WhileLoopNode{}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
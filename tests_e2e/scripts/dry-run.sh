@@ -0,0 +1,9 @@
+echo "Test dry run prints the execution plan without executing nodes"
+
+for TEST_NAME in for group_exec if; do
+  cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act" $TEST_NAME.act
+done
+
+#! test actrun --dry_run for.act
+#! test actrun --dry_run group_exec.act
+#! test actrun --dry_run if.act
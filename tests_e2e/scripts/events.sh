@@ -0,0 +1,36 @@
+echo "Test the JSON Lines event stream of a run"
+
+for TEST_NAME in if error_no_output; do
+  cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act" $TEST_NAME.act
+done
+
+print_events() {
+  python3 -c "
+import json, sys
+for line in open(sys.argv[1]):
+    e = json.loads(line)
+    assert e['run_id'] and e['execution_id'] and e['time']
+    print(e['event'], *[str(e[k]).replace('\n', ' ') for k in ('node_id', 'port', 'output', 'output_type', 'status', 'error', 'hint') if k in e])
+" "$1"
+}
+
+export FOO="Hello World!"
+#! test actrun --events events.jsonl if.act
+#! test print_events events.jsonl
+#! test actrun --events 3 error_no_output.act 3>events_fd.jsonl
+#! test print_events events_fd.jsonl
+
+# concurrent iterations run in their own execution states that link back to the root state
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}concurrent.act" concurrent.act
+print_executions() {
+  python3 -c "
+import json, sys
+events = [json.loads(line) for line in open(sys.argv[1])]
+root = events[0]['execution_id']
+children = {e['execution_id'] for e in events if e.get('parent_execution_id') == root}
+print('executions:', len({e['execution_id'] for e in events}), 'children of root:', len(children))
+" "$1"
+}
+
+#! test actrun --events concurrent.jsonl concurrent.act | sort
+#! test print_executions concurrent.jsonl
@@ -0,0 +1,29 @@
+echo "Test composite GitHub Actions with step conditions, outputs and nested actions"
+
+TEST_NAME=gh_composite
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+export HOME=$PWD/home
+export GITHUB_ACTIONS=true
+export GITHUB_WORKSPACE=$PWD/workspace
+export RUNNER_TEMP=$PWD/temp
+export RUNNER_TOOL_CACHE=$PWD/toolcache
+mkdir -p $GITHUB_WORKSPACE $RUNNER_TEMP $RUNNER_TOOL_CACHE
+
+# actions are loaded from ~/work/_actions/<owner>/<repo>/<ref>
+create_action() {
+  local dir=$HOME/work/_actions/acme/$1/v1
+  mkdir -p $dir
+  cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}_$1.yml" $dir/action.yml
+  git -C $dir init --quiet
+  git -C $dir add action.yml
+  git -C $dir -c user.name=test -c user.email=test@example.com commit --quiet -m "v1"
+  git -C $dir tag v1
+}
+
+create_action greet
+create_action upper
+create_action fail
+
+#! test actrun $TEST_NAME.act
@@ -0,0 +1,30 @@
+echo "Test the emulation of a GitHub Actions runner"
+
+TEST_NAME=gh_emulate
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}_pull_request.json" pull_request.json
+
+mkdir app
+cd app
+git init --quiet -b main
+git remote add origin https://github.com/acme/app.git
+git config user.name octocat
+git -c user.email=test@example.com commit --quiet --allow-empty -m "initial"
+
+#! test actrun --gh_emulate ../$TEST_NAME.act
+
+#! test actrun --gh_emulate --event pull_request --event_payload ../pull_request.json ../$TEST_NAME.act
+
+#! test actrun --gh_emulate --event workflow_dispatch ../$TEST_NAME.act
+
+# without a payload, the pull request merges the checked out branch into the default branch
+git checkout --quiet -b feature
+git -c user.email=test@example.com commit --quiet --allow-empty -m "Add a feature"
+#! test actrun --gh_emulate --event pull_request ../$TEST_NAME.act
+
+#! test actrun --event pull_request ../$TEST_NAME.act
+
+# no runner parses the output of an emulated run, so actrun masks the values itself
+sed -e 's/type: core\/start@v1/type: core\/gh-start@v1/' -e '/node: start$/{n;s/port: exec$/port: exec-on_push/}' "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}workflow_commands.act" > ../workflow_commands.act
+#! test actrun --gh_emulate ../workflow_commands.act
@@ -0,0 +1,33 @@
+echo "Test the pre and post steps of GitHub Actions"
+
+TEST_NAME=gh_post_steps
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+export HOME=$PWD/home
+export GITHUB_ACTIONS=true
+export GITHUB_WORKSPACE=$PWD/workspace
+export RUNNER_TEMP=$PWD/temp
+export RUNNER_TOOL_CACHE=$PWD/toolcache
+mkdir -p $GITHUB_WORKSPACE $RUNNER_TEMP $RUNNER_TOOL_CACHE
+
+# actions are loaded from ~/work/_actions/<owner>/<repo>/<ref>
+create_action() {
+  local dir=$HOME/work/_actions/acme/$1/v1
+  mkdir -p $dir
+  cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}_$1.yml" $dir/action.yml
+  echo "const fs = require('fs'); console.log('pre of ' + process.env.INPUT_NAME); fs.appendFileSync(process.env.GITHUB_STATE, 'started=' + process.env.INPUT_NAME + '\n');" > $dir/pre.js
+  echo "const fs = require('fs'); console.log('main of ' + process.env.INPUT_NAME + ', started: ' + process.env.STATE_started); fs.appendFileSync(process.env.GITHUB_STATE, 'key=' + process.env.INPUT_NAME + '-key\n');" > $dir/main.js
+  echo "console.log('post of ' + process.env.INPUT_NAME + ', started: ' + process.env.STATE_started + ', key: ' + process.env.STATE_key);" > $dir/post.js
+  git -C $dir init --quiet
+  git -C $dir add .
+  git -C $dir -c user.name=test -c user.email=test@example.com commit --quiet -m "v1"
+  git -C $dir tag v1
+}
+
+create_action stages
+create_action report
+
+#! test actrun $TEST_NAME.act
+
+#! test actrun --input code=1 $TEST_NAME.act
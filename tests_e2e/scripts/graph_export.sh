@@ -0,0 +1,11 @@
+echo "Test the export of a graph as a diagram"
+
+TEST_NAME=graph_export
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun graph export $TEST_NAME.act
+
+#! test actrun graph export --format mermaid $TEST_NAME.act
+
+#! test actrun graph export --format png $TEST_NAME.act
@@ -0,0 +1,23 @@
+echo "Test typed graph inputs from flags, arguments and inputs files"
+
+TEST_NAME=graph_inputs
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act --target=production --verbose
+
+#! test actrun --input target=staging --input replicas=3 --input tags=a,b $TEST_NAME.act
+
+echo '{"target": "production", "replicas": 5, "tags": ["x", "y"]}' > inputs.json
+
+#! test actrun --inputs_file inputs.json $TEST_NAME.act
+
+#! test actrun --inputs_file inputs.json --input replicas=1 $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act
+
+#! test actrun --input target=prod $TEST_NAME.act
+
+#! test actrun --input target=staging --input replicas=many $TEST_NAME.act
+
+#! test actrun --input missing=1 $TEST_NAME.act
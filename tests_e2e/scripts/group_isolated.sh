@@ -0,0 +1,17 @@
+echo "Test isolated group nodes"
+
+TEST_NAME=group_isolated
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+# env changes are only possible through GITHUB_ENV
+mkdir -p runner_temp
+export GITHUB_ACTIONS=true
+export RUNNER_TEMP=$PWD/runner_temp
+
+# FOO is not set after the group, but the group output is
+#! test actrun $TEST_NAME.act
+
+# without isolation, FOO leaks to the nodes after the group
+sed 's/isolated: true/isolated: false/' $TEST_NAME.act > shared.act
+#! test actrun shared.act
@@ -0,0 +1,7 @@
+echo "Test breaking a loop from the nodes after a group that was left in the loop body"
+
+TEST_NAME=group_loop_break
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act
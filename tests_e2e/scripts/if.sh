@@ -9,3 +9,8 @@ export FOO=false
 #! test actrun
 export FOO="Hello World!"
 #! test actrun
+
+# the expression is set in the node, a connection to it is rejected
+sed 's/port: condition$/port: expression/' $TEST_NAME.act > connected.act
+unset ACT_GRAPH_FILE
+#! test actrun validate connected.act
@@ -0,0 +1,19 @@
+echo "Test the import of GitHub Actions workflows"
+
+TEST_NAME=import_workflow
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.yml" $TEST_NAME.yml
+
+#! test actrun import workflow $TEST_NAME.yml --job test -o test.act
+
+#! test actrun import workflow $TEST_NAME.yml --job release
+
+#! test actrun import workflow $TEST_NAME.yml
+
+git init --quiet -b main
+git remote add origin https://github.com/acme/app.git
+git -c user.name=octocat -c user.email=test@example.com commit --quiet --allow-empty -m "initial"
+mkdir sub
+
+#! test actrun --gh_emulate test.act
+
+#! test actrun --gh_emulate --event pull_request test.act
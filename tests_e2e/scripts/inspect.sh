@@ -0,0 +1,9 @@
+echo "Test the description of a graph's interface"
+
+TEST_NAME=inspect
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun inspect $TEST_NAME.act
+
+#! test actrun inspect --format json $TEST_NAME.act
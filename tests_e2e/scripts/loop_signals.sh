@@ -0,0 +1,19 @@
+echo "Test loop continue and break nodes"
+
+TEST_NAME=loop_signals
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act
+
+# the named loop doesn't execute the break node
+sed 's/loop: outer/loop: concurrent/' $TEST_NAME.act > named.act
+#! test actrun named.act
+
+# break node outside of a loop
+sed '/node: start$/,/node: loop$/s/node: loop$/node: break/' $TEST_NAME.act > outside.act
+#! test actrun outside.act
+
+# several workers run the concurrent loop, only the break result is stable
+sed 's/worker_count: 1$/worker_count: 4/' $TEST_NAME.act > workers.act
+#! test actrun workers.act 2>&1 | grep "^concurrent loop"
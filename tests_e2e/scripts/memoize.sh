@@ -0,0 +1,29 @@
+echo "Test nodes that cache their results"
+
+TEST_NAME=memoize
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+# env changes are only possible through GITHUB_ENV
+mkdir -p runner_temp
+export GITHUB_ACTIONS=true
+export RUNNER_TEMP=$PWD/runner_temp
+
+echo "frame 1" > scene.txt
+#! test actrun --cache_dir=cache $TEST_NAME.act
+
+# the second run restores the output and env of the cached node
+#! test actrun --cache_dir=cache $TEST_NAME.act
+
+# the file the node refers to has changed
+echo "frame 2" >> scene.txt
+#! test actrun --cache_dir=cache $TEST_NAME.act
+
+# loops can't be cached
+sed 's/type: core\/run@v1/type: core\/for-loop@v1/' $TEST_NAME.act > loop.act
+#! test actrun validate loop.act
+
+# a node that sets a secret isn't cached
+sed 's/echo "rendering $1";/echo "::add-mask::hunter2"; echo "TOKEN=hunter2" >> "$GITHUB_ENV";/' $TEST_NAME.act > secret.act
+#! test actrun --cache_dir=secret_cache secret.act
+#! test ls secret_cache
@@ -0,0 +1,15 @@
+echo "Test the migration of deprecated node types"
+
+TEST_NAME=migrate
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act
+
+#! test actrun migrate --dry_run $TEST_NAME.act
+
+#! test actrun migrate $TEST_NAME.act
+
+#! test actrun migrate $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act
@@ -0,0 +1,29 @@
+echo "Test resuming an interrupted run from its journal"
+
+TEST_NAME=resume
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun --run_dir runs $TEST_NAME.act 2>&1 | sed -E 's/[0-9a-f-]{36}/RUN_ID/g'
+
+print_journal() {
+  python3 -c "import json, glob; j = json.load(open(glob.glob('runs/*/journal.json')[0])); print(j['status'], j.get('resume_from', '-')); [print(n['node_id'], n['port'], n['resumable'], sorted(n.get('outputs', {}))) for n in j['nodes']]"
+}
+
+#! test print_journal
+
+RUN_ID=$(ls runs)
+
+#! test actrun --run_dir runs --resume $RUN_ID $TEST_NAME.act 2>&1 | sed -E 's/[0-9a-f-]{36}/RUN_ID/g'
+#! test print_journal
+#! test actrun --run_dir runs --resume $RUN_ID $TEST_NAME.act 2>&1 | sed -E 's/[0-9a-f-]{36}/RUN_ID/g'
+
+# a secret in the environment isn't written to the journal, the run resumes at the node that set it
+rm -rf runs interrupted.txt
+mkdir -p runner_temp
+export GITHUB_ACTIONS=true
+export RUNNER_TEMP=$PWD/runner_temp
+sed 's/script: print("step a")/script: import os; print("::add-mask::hunter2"); open(os.environ["GITHUB_ENV"], "a").write("TOKEN=hunter2\\n")/' $TEST_NAME.act > secret.act
+#! test actrun --run_dir runs secret.act 2>&1 | sed -E 's/[0-9a-f-]{36}/RUN_ID/g'
+#! test print_journal
+#! test grep -c hunter2 runs/*/journal.json
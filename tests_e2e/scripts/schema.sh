@@ -0,0 +1,5 @@
+echo "Test the JSON Schema of node definitions"
+
+#! test actrun schema node
+
+#! test actrun schema unknown
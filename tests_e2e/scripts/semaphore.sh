@@ -0,0 +1,16 @@
+echo "Test named semaphores of a graph"
+
+TEST_NAME=semaphore
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+# the branches of the loop run in any order, so sort the output
+#! test actrun $TEST_NAME.act | sort
+
+# the semaphore isn't defined in the graph
+sed -e 's/name: gpu/name: license-server/' -e 's/worker_count: 4/worker_count: 1/' $TEST_NAME.act > unknown.act
+#! test actrun unknown.act
+
+# the capacity of a semaphore must be positive
+sed 's/gpu: 1/gpu: 0/' $TEST_NAME.act > capacity.act
+#! test actrun validate capacity.act
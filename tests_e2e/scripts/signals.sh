@@ -0,0 +1,23 @@
+echo "Test cancelling a run with a signal"
+
+TEST_NAME=signals
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+# the child ignores SIGTERM, so it is killed once the grace period is over
+interrupt_run() {
+  actrun $TEST_NAME.act > run.log 2>&1 &
+  ACTRUN_PID=$!
+  while [ ! -s child.pid ]; do sleep 0.1; done
+  kill -INT $ACTRUN_PID
+  wait $ACTRUN_PID
+  echo "exit code: $?"
+  grep -o "cancelling the run" run.log
+  grep -c "should not be printed" run.log
+  # a killed child might remain a zombie if nothing reaps orphans
+  CHILD_STATE=$(ps -o stat= -p $(cat child.pid) | cut -c1)
+  if [ -z "$CHILD_STATE" ] || [ "$CHILD_STATE" = "Z" ]; then echo "child terminated"; else echo "child still running"; fi
+  rm child.pid
+}
+
+#! test interrupt_run
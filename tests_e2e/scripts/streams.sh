@@ -0,0 +1,11 @@
+echo "Test that streams are closed when they are no longer needed"
+
+TEST_NAME=streams
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+echo "hello" > hello.txt
+
+# the streams of the iterations that skip printing are never read,
+# the stream of the last iteration is read after the loop
+#! test actrun $TEST_NAME.act
@@ -0,0 +1,23 @@
+echo "Test group nodes that reference a graph file"
+
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}subgraph.act" subgraph.act
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}subgraph_greet.act" subgraph_greet.act
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}subgraph_cycle.act" subgraph_cycle.act
+
+export GREETING='echo "hello from the referenced graph"'
+#! test actrun subgraph.act
+
+# references are resolved against the directory of the graph file
+mkdir sub
+sed 's/ref: subgraph_greet.act/ref: .\/lib.act/' subgraph.act > sub/main.act
+cp subgraph_greet.act sub/lib.act
+#! test actrun sub/main.act
+
+# a graph that references itself
+sed 's/subgraph_greet.act/subgraph_cycle.act/' subgraph.act > cycle.act
+#! test actrun validate cycle.act
+
+# the input of the referenced graph was renamed
+sed 's/message/text/g' subgraph_greet.act > greet_changed.act
+mv greet_changed.act subgraph_greet.act
+#! test actrun subgraph.act
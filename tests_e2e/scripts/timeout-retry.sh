@@ -0,0 +1,13 @@
+echo "Test node timeout and retries"
+
+TEST_NAME=timeout-retry
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+export ACT_GRAPH_FILE=$TEST_NAME.act
+
+#! test actrun
+
+# loops hand over before they are done, so they can't have a timeout or retries
+sed 's/type: core\/run@v1/type: core\/for-loop@v1/' $TEST_NAME.act > loop.act
+unset ACT_GRAPH_FILE
+#! test actrun validate loop.act
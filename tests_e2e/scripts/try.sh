@@ -0,0 +1,21 @@
+echo "Test try node with catch and finally branches"
+
+TEST_NAME=try
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+export MODE=fail
+#! test actrun $TEST_NAME.act
+
+# the finally branch also runs if the run is cancelled
+interrupt_run() {
+  MODE=sleep actrun $TEST_NAME.act > run.log 2>&1 &
+  ACTRUN_PID=$!
+  while [ ! -f started ]; do sleep 0.1; done
+  kill -TERM $ACTRUN_PID
+  wait $ACTRUN_PID
+  echo "exit code: $?"
+  grep -c "finally cleans up" run.log
+}
+
+#! test interrupt_run
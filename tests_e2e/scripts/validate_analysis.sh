@@ -0,0 +1,13 @@
+echo "Test the analysis of a graph by validate"
+
+TEST_NAME=validate_analysis
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun validate $TEST_NAME.act
+
+#! test actrun validate --format json $TEST_NAME.act
+
+# the required inputs of nodes that are never executed are not checked
+cp "${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}error_no_output.act" error_no_output.act
+#! test actrun validate error_no_output.act
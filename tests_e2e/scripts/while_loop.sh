@@ -0,0 +1,22 @@
+echo "Test while loop node"
+
+TEST_NAME=while_loop
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+# the polling loop waits for READY to be set via GITHUB_ENV
+mkdir -p runner_temp
+export GITHUB_ACTIONS=true
+export RUNNER_TEMP=$PWD/runner_temp
+
+#! test actrun $TEST_NAME.act
+
+# the do-while loop needs 3 iterations
+rm -f ready
+sed 's/max_iterations: 1000/max_iterations: 2/' $TEST_NAME.act > max.act
+#! test actrun max.act
+
+# the expression is set in the node, a connection to it is rejected
+sed 's/port: condition$/port: expression/' $TEST_NAME.act > connected.act
+unset ACT_GRAPH_FILE
+#! test actrun validate connected.act
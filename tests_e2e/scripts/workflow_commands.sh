@@ -0,0 +1,14 @@
+echo "Test the workflow commands that scripts write to stdout"
+
+TEST_NAME=workflow_commands
+GRAPH_FILE="${ACT_GRAPH_FILES_DIR}${PATH_SEPARATOR}${TEST_NAME}.act"
+cp $GRAPH_FILE $TEST_NAME.act
+
+#! test actrun $TEST_NAME.act
+
+# a GitHub workflow that runs locally masks the values itself, no runner parses its output
+sed -e 's/type: core\/start@v1/type: core\/gh-start@v1/' -e '/node: start$/{n;s/port: exec$/port: exec-on_push/}' $TEST_NAME.act > gh_workflow.act
+mkdir runner_temp
+export GITHUB_EVENT_NAME=push
+export RUNNER_TEMP="$(pwd)/runner_temp"
+#! test actrun gh_workflow.act
@@ -3,6 +3,7 @@
 package tests_unit
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/actionforge/actrun-cli/core"
@@ -62,3 +63,46 @@ func Test_NewNodeInstance(t *testing.T) {
 		t.Errorf("Expected node name to be 'core/run@v1', got '%s'", n.GetNodeTypeId())
 	}
 }
+
+// The semaphores of the graph of a group are shared by the whole run.
+func TestGroupSemaphores(t *testing.T) {
+	graph := func(parentCapacity int) map[string]any {
+		return map[string]any{
+			"entry":      "start",
+			"type":       "generic",
+			"semaphores": map[string]any{"gpu": parentCapacity},
+			"nodes": []any{
+				map[string]any{"id": "start", "type": "core/start@v1"},
+				map[string]any{
+					"id":   "group",
+					"type": "core/group@v1",
+					"graph": map[string]any{
+						"entry":       "print",
+						"type":        "group",
+						"semaphores":  map[string]any{"gpu": 1, "license-server": 4},
+						"nodes":       []any{map[string]any{"id": "print", "type": "core/print@v1"}},
+						"connections": []any{},
+						"executions":  []any{},
+					},
+				},
+			},
+			"connections": []any{},
+			"executions":  []any{},
+		}
+	}
+
+	ag, errs := core.LoadGraph(graph(1), nil, "", core.GraphLoadContext{}, false)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if ag.Semaphores["gpu"] != 1 || ag.Semaphores["license-server"] != 4 {
+		t.Errorf("unexpected semaphores %v", ag.Semaphores)
+	}
+
+	_, errs = core.LoadGraph(graph(2), nil, "", core.GraphLoadContext{}, false)
+	if len(errs) == 0 {
+		t.Error("expected an error for a semaphore with different capacities")
+	} else if !strings.Contains(errs[0].Error(), "semaphore 'gpu'") {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	// initialize all nodes
 
+	"github.com/actionforge/actrun-cli/core"
 	gh_action_yml "github.com/actionforge/actrun-cli/github/action.yml"
 	gh_workflow_yml "github.com/actionforge/actrun-cli/github/workflow.yml"
 
@@ -92,3 +93,75 @@ func TestGhActionYml(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// Import every job of the sample workflows and check that the executions
+// of the graphs only connect nodes of the graph.
+func TestImportGhWorkflows(t *testing.T) {
+	projectRoot, err := findGoModFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(projectRoot, "github", "workflow.yml", "*.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var workflow gh_workflow_yml.GhWorkflow
+		err = yaml.Unmarshal(content, &workflow)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for jobId, job := range workflow.Jobs {
+			graph, _, err := core.ImportWorkflow(content, jobId)
+			if job.Uses != "" {
+				if err == nil {
+					t.Errorf("%s: expected an error for job '%s' that calls a reusable workflow", filepath.Base(file), jobId)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: failed to import job '%s': %v", filepath.Base(file), jobId, err)
+				continue
+			}
+
+			var g struct {
+				Nodes []struct {
+					Id string `yaml:"id"`
+				} `yaml:"nodes"`
+				Executions []struct {
+					Src struct {
+						Node string `yaml:"node"`
+					} `yaml:"src"`
+					Dst struct {
+						Node string `yaml:"node"`
+					} `yaml:"dst"`
+				} `yaml:"executions"`
+			}
+			err = yaml.Unmarshal(graph, &g)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			nodeIds := map[string]bool{}
+			for _, n := range g.Nodes {
+				if nodeIds[n.Id] {
+					t.Errorf("%s: job '%s' has node id '%s' more than once", filepath.Base(file), jobId, n.Id)
+				}
+				nodeIds[n.Id] = true
+			}
+			for _, e := range g.Executions {
+				if !nodeIds[e.Src.Node] || !nodeIds[e.Dst.Node] {
+					t.Errorf("%s: job '%s' connects unknown nodes '%s' -> '%s'", filepath.Base(file), jobId, e.Src.Node, e.Dst.Node)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+//go:build tests_unit
+
+package tests_unit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/actionforge/actrun-cli/core"
+
+	// initialize all nodes
+	_ "github.com/actionforge/actrun-cli/nodes"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectorStub is a minimal OTLP/HTTP collector that keeps all received spans.
+type collectorStub struct {
+	lock  sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collectorStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req coltracepb.ExportTraceServiceRequest
+	err = proto.Unmarshal(body, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.lock.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(nil)
+}
+
+func Test_TraceExport(t *testing.T) {
+	projectRoot, err := findGoModFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collector := &collectorStub{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	t.Setenv("BOOL_VAR", "true")
+
+	graphFile := filepath.Join(projectRoot, "tests_e2e", "scripts", "group_exec.act")
+	err = core.RunGraphFromFile(context.Background(), graphFile, core.RunOpts{
+		OtlpEndpoint: srv.URL,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]*tracepb.Span{}
+	for _, span := range collector.spans {
+		spans[span.Name] = span
+	}
+
+	run, ok := spans["run "+graphFile]
+	if !ok {
+		t.Fatalf("run span not exported, got %d spans", len(collector.spans))
+	}
+
+	// node name -> name of the expected parent span
+	expected := map[string]string{
+		"start":                       run.Name,
+		"group-v1-kiwi-squirrel-plum": run.Name,
+		"group-v1-kiwi-squirrel-plum/branch-v1-squirrel-shark-koala": "group-v1-kiwi-squirrel-plum",
+		"run-v1-pink-blue-koala": run.Name,
+	}
+
+	for name, parentName := range expected {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("span '%s' not exported", name)
+			continue
+		}
+		if string(span.ParentSpanId) != string(spans[parentName].SpanId) {
+			t.Errorf("span '%s' is not a child of '%s'", name, parentName)
+		}
+		if string(span.TraceId) != string(run.TraceId) {
+			t.Errorf("span '%s' is not part of the run trace", name)
+		}
+	}
+
+	group := spans["group-v1-kiwi-squirrel-plum"]
+	branch := spans["group-v1-kiwi-squirrel-plum/branch-v1-squirrel-shark-koala"]
+	if group.EndTimeUnixNano < branch.EndTimeUnixNano {
+		t.Errorf("group span ended before the nodes inside the group")
+	}
+
+	for _, attr := range branch.Attributes {
+		if attr.Key == "actrun.node.type" && attr.Value.GetStringValue() != "core/branch@v1" {
+			t.Errorf("unexpected node type '%s'", attr.Value.GetStringValue())
+		}
+	}
+}
+
+func Test_TraceExportInvalidEndpoint(t *testing.T) {
+	projectRoot, err := findGoModFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runDir := t.TempDir()
+	eventsFile := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// the run fails after the journal and the event stream were opened,
+	// both must still record how the run ended
+	graphFile := filepath.Join(projectRoot, "tests_e2e", "scripts", "group_exec.act")
+	err = core.RunGraphFromFile(context.Background(), graphFile, core.RunOpts{
+		RunDir:       runDir,
+		Events:       eventsFile,
+		OtlpEndpoint: "not-a-url",
+	}, nil)
+	if err == nil {
+		t.Fatal("expected an error for the invalid endpoint")
+	}
+
+	events, err := os.ReadFile(eventsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(events), `"event":"run_finished"`) {
+		t.Errorf("events have no 'run_finished' record:\n%s", events)
+	}
+
+	journals, err := filepath.Glob(filepath.Join(runDir, "*", "*"))
+	if err != nil || len(journals) != 1 {
+		t.Fatalf("expected one journal file, got %v (%v)", journals, err)
+	}
+	journal, err := os.ReadFile(journals[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(journal), string(core.JournalStatusRunning)) {
+		t.Errorf("journal is still running:\n%s", journal)
+	}
+}
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -62,11 +65,91 @@ type lockedWriter struct {
 func (lw *lockedWriter) Write(p []byte) (n int, err error) {
 	lw.mux.Lock()
 	defer lw.mux.Unlock()
+
+	if len(logMasks) > 0 {
+		masked := string(p)
+		for _, mask := range logMasks {
+			masked = strings.ReplaceAll(masked, mask, "***")
+		}
+		_, err = lw.w.Write([]byte(masked))
+		return len(p), err
+	}
 	return lw.w.Write(p)
 }
 
+// LineWriter passes the output of a process to `w` in complete lines. The logs mask values
+// per write, so a masked value that a process writes in two chunks would leak otherwise.
+// `Close` writes the last line if it doesn't end with a newline.
+type LineWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w}
+}
+
+func (lw *LineWriter) Write(p []byte) (int, error) {
+	lw.buf = append(lw.buf, p...)
+	i := bytes.LastIndexByte(lw.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	_, err := lw.w.Write(lw.buf[:i+1])
+	lw.buf = lw.buf[i+1:]
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (lw *LineWriter) Close() error {
+	if len(lw.buf) == 0 {
+		return nil
+	}
+	_, err := lw.w.Write(lw.buf)
+	lw.buf = nil
+	return err
+}
+
+// values that are replaced with `***` in all logs, see `AddLogMask`
+var logMasks []string
+
+// AddLogMask redacts a value, like a token, from everything that is logged afterwards.
+func AddLogMask(value string) {
+	logMux.Lock()
+	defer logMux.Unlock()
+
+	value = strings.TrimSpace(value)
+	if value == "" || slices.Contains(logMasks, value) {
+		return
+	}
+	logMasks = append(logMasks, value)
+
+	// replace longer values first, so a value that contains another one is fully redacted
+	slices.SortFunc(logMasks, func(a, b string) int {
+		return len(b) - len(a)
+	})
+}
+
+// ContainsLogMask returns true if `s` contains a value that is redacted from the logs.
+func ContainsLogMask(s string) bool {
+	logMux.Lock()
+	defer logMux.Unlock()
+
+	for _, mask := range logMasks {
+		if strings.Contains(s, mask) {
+			return true
+		}
+	}
+	return false
+}
+
+// the lock of the log writers, it also guards `logMasks`
+var logMux = &sync.Mutex{}
+
 func init() {
-	mux := &sync.Mutex{}
+	mux := logMux
 
 	// Logus is thread-safe except when it isn't :-|
 	// Ocassionally I still saw concurrent outputs
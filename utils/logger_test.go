@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+type recordingWriter struct {
+	writes []string
+}
+
+func (r *recordingWriter) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func TestLineWriter(t *testing.T) {
+	var rec recordingWriter
+	w := NewLineWriter(&rec)
+
+	for _, chunk := range []string{"tok", "en: sec", "ret\nnext ", "line\nlast"} {
+		_, err := w.Write([]byte(chunk))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"token: secret\n", "next line\n", "last"}
+	if len(rec.writes) != len(want) {
+		t.Fatalf("got writes %q, want %q", rec.writes, want)
+	}
+	for i := range want {
+		if rec.writes[i] != want[i] {
+			t.Fatalf("got writes %q, want %q", rec.writes, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	empty := NewLineWriter(&buf)
+	if err := empty.Close(); err != nil || buf.Len() != 0 {
+		t.Fatalf("closing an empty writer wrote %q", buf.String())
+	}
+}
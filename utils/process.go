@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ProcessGracePeriod is the time a cancelled child process and its own children
+// get to exit after they were asked to terminate, before they are killed.
+var ProcessGracePeriod = 5 * time.Second
+
+var (
+	terminatedLock   sync.Mutex
+	terminatedGroups = map[int]bool{}
+)
+
+// SetupProcessGroup prepares a command created with `exec.CommandContext` so that
+// cancelling the context terminates the whole process tree of the command, not only
+// the process itself. See `setProcessGroup` for the platform specific details.
+func SetupProcessGroup(cmd *exec.Cmd) {
+	setProcessGroup(cmd)
+
+	// don't wait forever for the output pipes if a
+	// grandchild process keeps them open after termination
+	cmd.WaitDelay = 2 * ProcessGracePeriod
+}
+
+// RunProcess runs a command that was prepared with `SetupProcessGroup`. Once the command
+// was waited for, its process group is no longer tracked, so the kill after the grace
+// period can't hit another process group that got the same id in the meantime.
+func RunProcess(cmd *exec.Cmd) error {
+	err := cmd.Run()
+	if cmd.Process != nil {
+		terminatedLock.Lock()
+		delete(terminatedGroups, cmd.Process.Pid)
+		terminatedLock.Unlock()
+	}
+	return err
+}
+
+// KillProcessGroups immediately kills all process groups that are
+// terminating, eg. when the user doesn't want to wait any longer.
+func KillProcessGroups() {
+	terminatedLock.Lock()
+	defer terminatedLock.Unlock()
+
+	for pgid := range terminatedGroups {
+		killProcessGroup(pgid)
+		delete(terminatedGroups, pgid)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !windows
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup starts the command in its own process group. On cancellation,
+// the group receives SIGTERM and, if it's still tracked after the grace period,
+// SIGKILL. `RunProcess` stops tracking the group once the command was waited for.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+
+		terminatedLock.Lock()
+		terminatedGroups[pgid] = true
+		terminatedLock.Unlock()
+
+		time.AfterFunc(ProcessGracePeriod, func() {
+			terminatedLock.Lock()
+			defer terminatedLock.Unlock()
+
+			if terminatedGroups[pgid] {
+				killProcessGroup(pgid)
+				delete(terminatedGroups, pgid)
+			}
+		})
+
+		err := syscall.Kill(-pgid, syscall.SIGTERM)
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+}
+
+func killProcessGroup(pgid int) {
+	_ = syscall.Kill(-pgid, syscall.SIGKILL)
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRunProcessForgetsTerminatedGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sleep", "10")
+	SetupProcessGroup(cmd)
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	if err := RunProcess(cmd); err == nil {
+		t.Fatal("expected the cancelled command to fail")
+	}
+
+	terminatedLock.Lock()
+	defer terminatedLock.Unlock()
+	if terminatedGroups[cmd.Process.Pid] {
+		t.Errorf("process group %d is still tracked after the command was waited for", cmd.Process.Pid)
+	}
+}
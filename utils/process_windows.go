@@ -0,0 +1,15 @@
+//go:build windows
+
+package utils
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup keeps the default cancellation on Windows, which kills the
+// process itself. Windows has no process groups that can be signalled as a whole.
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+func killProcessGroup(pgid int) {
+}